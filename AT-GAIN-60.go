@@ -4,15 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/byuoitav/common/log"
+	"github.com/byuoitav/common/structs"
 )
 
 // Amp60 represents an Atlona 60 watt amplifier
@@ -20,6 +22,9 @@ type Amp60 struct {
 	Username string
 	Password string
 	Address  string
+
+	sessionMu sync.Mutex
+	loggedIn  bool
 }
 
 // AmpStatus represents the current amp status
@@ -29,16 +34,148 @@ type AmpStatus struct {
 	MACAddress    string `json:"103"`
 	SerialNumber  string `json:"104"`
 	OperatingTime string `json:"105"`
+
+	// Uptime is OperatingTime parsed into a duration
+	Uptime time.Duration `json:"-"`
 }
 
-// AmpAudio represents an audio response from an Atlona 60 watt amp
-type AmpAudio struct {
-	Volume string `json:"608,omitempty"`
-	Muted  string `json:"609,omitempty"`
+var operatingTimeRegex = regexp.MustCompile(`(\d+)\s*([a-zA-Z]*)`)
+
+// parseOperatingTime parses the amp's operating time, which is either h:m[:s]
+// or a list of numbers with units (ie, "3d 04h 12m"). a bare number is hours.
+func parseOperatingTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("operating time is empty")
+	}
+
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid operating time %q", s)
+		}
+
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+
+		var d time.Duration
+		for i, part := range parts {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return 0, fmt.Errorf("invalid operating time %q", s)
+			}
+
+			d += time.Duration(n) * units[i]
+		}
+
+		return d, nil
+	}
+
+	matches := operatingTimeRegex.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid operating time %q", s)
+	}
+
+	var d time.Duration
+	for _, match := range matches {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid operating time %q", s)
+		}
+
+		var unit time.Duration
+		switch strings.ToLower(match[2]) {
+		case "d", "day", "days":
+			unit = 24 * time.Hour
+		case "", "h", "hr", "hrs", "hour", "hours":
+			unit = time.Hour
+		case "m", "min", "mins", "minute", "minutes":
+			unit = time.Minute
+		case "s", "sec", "secs", "second", "seconds":
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid operating time %q: unknown unit %q", s, match[2])
+		}
+
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
+
+// EQPreset is one of the equalizer presets built into the amp
+type EQPreset int
+
+// EQ presets available on the amp
+const (
+	EQFlat EQPreset = iota
+	EQVoice
+	EQMusic
+	EQMovie
+	EQCustom
+)
+
+var eqPresetNames = map[EQPreset]string{
+	EQFlat:   "flat",
+	EQVoice:  "voice",
+	EQMusic:  "music",
+	EQMovie:  "movie",
+	EQCustom: "custom",
+}
+
+func (p EQPreset) String() string {
+	if name, ok := eqPresetNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("EQPreset(%d)", int(p))
+}
+
+// AmpInput is one of the inputs on the amp
+type AmpInput int
+
+// Inputs on the amp
+const (
+	AmpInputAnalog AmpInput = iota
+	AmpInputDigital
+)
+
+func (i AmpInput) String() string {
+	switch i {
+	case AmpInputAnalog:
+		return "analog"
+	case AmpInputDigital:
+		return "digital"
+	default:
+		return fmt.Sprintf("AmpInput(%d)", int(i))
+	}
+}
+
+// AmpStandbyTimers are the automatic standby/wake settings on the amp.
+// A zero duration disables that timer.
+type AmpStandbyTimers struct {
+	// AutoStandby is how long the amp waits without a signal before going into standby (minute resolution)
+	AutoStandby time.Duration
+	// AutoWake is how long a signal has to be present before the amp wakes up from standby (second resolution)
+	AutoWake time.Duration
+}
+
+// limits on the standby timers, from the amp's web page
+const (
+	ampMaxAutoStandby = 120 * time.Minute
+	ampMaxAutoWake    = 60 * time.Second
+)
+
+// AmpNetworkSettings are the network settings on the amp
+type AmpNetworkSettings struct {
+	DHCP      bool
+	IPAddress string
+	Netmask   string
+	Gateway   string
+	DNS       string
 }
 
 type loginResult struct {
-	Login bool
+	Login *bool
 }
 
 func getR() string {
@@ -50,170 +187,476 @@ func getURL(address, endpoint string) string {
 }
 
 func (a *Amp60) getLoginUrl() string {
-	return "http://" + a.Address + "/action=compare&701=" + a.Username + "&702=" + a.Password + "&r=" + getR()
+	return "http://" + a.Address + "/action=compare&701=" + url.QueryEscape(a.Username) + "&702=" + url.QueryEscape(a.Password) + "&r=" + getR()
 }
 
-func (a *Amp60) sendReq(ctx context.Context, endpoint string) ([]byte, error) {
-	// checking to validate that it is logged in
-	err := a.login(ctx)
-	if err != nil {
-		fmt.Errorf("Login failed to device: %v", err)
+// sessionExpired reports whether a response from the amp means that we are no longer logged in.
+// the amp answers requests from a timed out session with a 404, or with {"Login":false}
+func sessionExpired(status int, body []byte) bool {
+	if status == http.StatusNotFound {
+		return true
 	}
 
-	var toReturn []byte
-	ampUrl := getURL(a.Address, endpoint)
-	Client := http.Client{Timeout: time.Second * 10}
+	var res loginResult
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false
+	}
+
+	return res.Login != nil && !*res.Login
+}
+
+// ampCredentialRegex matches the username (701) and password (702) values in a url, which are sent
+// when logging in and when changing the password
+var ampCredentialRegex = regexp.MustCompile(`\b(70[12]=)[^&]*`)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", ampUrl, nil)
-	req.Header.Set("Context-type", "application/json")
-	//req, err := http.NewRequest("GET", ampUrl, nil)
-	log.L.Debug("Request Output: %v", req)
+// redactAmpURL returns ampURL with the username and password values hidden, so that it can be logged
+func redactAmpURL(ampURL string) string {
+	return ampCredentialRegex.ReplaceAllString(ampURL, "${1}REDACTED")
+}
+
+func (a *Amp60) get(ctx context.Context, ampURL string) (int, []byte, error) {
+	log.L.Debugf("Request Output: %v", redactAmpURL(ampURL))
+
+	status, body, err := ampGet(ctx, ampURL)
 	if err != nil {
-		return toReturn, fmt.Errorf("unable to make new http request: %w", err)
+		return 0, nil, fmt.Errorf("unable to perform request: %w", err)
+	}
+
+	log.L.Debugf("Response: %v %s\n", status, body)
+	return status, body, nil
+}
+
+func (a *Amp60) sendReq(ctx context.Context, endpoint string) ([]byte, error) {
+	// only log in if we don't already have a valid session
+	if err := a.ensureLogin(ctx); err != nil {
+		return nil, err
 	}
-	resp, err := Client.Do(req)
-	log.L.Debug("RESP Output: %v", resp)
+
+	status, body, err := a.get(ctx, getURL(a.Address, endpoint))
 	if err != nil {
-		if nerr, ok := err.(*url.Error); ok {
-			fmt.Printf("%v\n", nerr.Err)
-			if !strings.Contains(nerr.Err.Error(), "malformed") {
-				return toReturn, fmt.Errorf("unable to perform request: %w", err)
-			}
-		} else {
-			return toReturn, fmt.Errorf("unable to perform request: %w", err)
-		}
-		return toReturn, nil
+		return nil, err
+	}
+
+	if !sessionExpired(status, body) {
+		return checkAmpStatus(status, body)
+	}
+
+	// the session timed out on the amp, log in again and retry once
+	a.invalidateSession()
+	if err := a.ensureLogin(ctx); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
-	toReturn, err = ioutil.ReadAll(resp.Body)
-	s := string(toReturn)
-	log.L.Infof("Response: %v\n", s)
 
+	status, body, err = a.get(ctx, getURL(a.Address, endpoint))
 	if err != nil {
-		return toReturn, fmt.Errorf("unable to read resp body: %w", err)
+		return nil, err
 	}
-	return toReturn, nil
+
+	if status == http.StatusNotFound {
+		// we just logged in, so the amp doesn't have this endpoint
+		return nil, fmt.Errorf("%s is not supported by %s: %v response received", endpoint, a.Address, status)
+	}
+
+	if sessionExpired(status, body) {
+		return nil, fmt.Errorf("session on %s expired immediately after logging in", a.Address)
+	}
+
+	return checkAmpStatus(status, body)
 }
 
-// login for device
+// checkAmpStatus returns body, or an error if status isn't a 2xx
+func checkAmpStatus(status int, body []byte) ([]byte, error) {
+	if status/100 != 2 {
+		return nil, fmt.Errorf("%v response received. body: %s", status, body)
+	}
+
+	return body, nil
+}
+
+func (a *Amp60) invalidateSession() {
+	a.sessionMu.Lock()
+	a.loggedIn = false
+	a.sessionMu.Unlock()
+}
+
+// ensureLogin logs in to the amp if there isn't already a valid session
+func (a *Amp60) ensureLogin(ctx context.Context) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	if a.loggedIn {
+		return nil
+	}
+
+	if err := a.login(ctx); err != nil {
+		return fmt.Errorf("unable to log in to %s: %w", a.Address, err)
+	}
+
+	a.loggedIn = true
+	return nil
+}
+
+// login for device. sessionMu must be held by the caller
 func (a *Amp60) login(ctx context.Context) error {
-	// Check if we are currently logged in
-	resp, err := http.Get(a.getLoginUrl())
+	status, body, err := a.get(ctx, a.getLoginUrl())
 	if err != nil {
-		return fmt.Errorf("Unable to log in: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
-	out, err := ioutil.ReadAll(resp.Body)
-	s := string(out)
-	if err != nil {
-		fmt.Errorf("Cannot read body of test: %v", err)
+
+	if status/100 != 2 {
+		return fmt.Errorf("%v response received. body: %s", status, body)
 	}
 
-	if strings.Contains(s, "404") == true {
-		var toReturn []byte
-		loginUrl := a.getLoginUrl()
-		Client := http.Client{Timeout: time.Second * 10}
-		req, err := http.NewRequestWithContext(ctx, "GET", loginUrl, nil)
-		if err != nil {
-			return fmt.Errorf("Unable to create request: %v", err)
-		}
-		resp, err := Client.Do(req)
-		if err != nil {
-			return fmt.Errorf("Unable to connect to device: %v", err)
-		}
-		defer resp.Body.Close()
-		toReturn, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("Cannot read the body of the response")
-		}
-		data := loginResult{}
-		json.Unmarshal(toReturn, &data)
-		if data.Login != true {
-			return fmt.Errorf("Not able to login: %v", err)
-		}
-		return nil
+	var res loginResult
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("unable to unmarshal login response: %w", err)
+	}
+
+	if res.Login == nil || !*res.Login {
+		return &AuthError{Address: a.Address, Username: a.Username}
 	}
 
 	return nil
+}
+
+// GetStatus gets the current amp status
+func (a *Amp60) GetStatus(ctx context.Context) (AmpStatus, error) {
+	var info AmpStatus
 
+	vals, err := a.readRegisters(ctx, AmpRegModel, AmpRegFirmware, AmpRegMACAddress, AmpRegSerialNumber, AmpRegOperatingTime)
+	if err != nil {
+		return info, fmt.Errorf("unable to get info: %w", err)
+	}
+
+	info.Model = vals[AmpRegModel.Key].(string)
+	info.Firmware = vals[AmpRegFirmware.Key].(string)
+	info.MACAddress = vals[AmpRegMACAddress.Key].(string)
+	info.SerialNumber = vals[AmpRegSerialNumber.Key].(string)
+	info.OperatingTime = vals[AmpRegOperatingTime.Key].(string)
+
+	if info.OperatingTime != "" {
+		// the raw operating time is still in info, so a format we don't know shouldn't fail the whole call
+		if info.Uptime, err = parseOperatingTime(info.OperatingTime); err != nil {
+			log.L.Warnf("unable to parse operating time on %s: %s", a.Address, err)
+		}
+	}
+
+	return info, nil
 }
 
 // GetInfo gets the current amp status
 func (a *Amp60) GetInfo(ctx context.Context) (interface{}, error) {
-	resp, err := a.sendReq(ctx, "devicestatus_get")
+	info, err := a.GetStatus(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get info: %w", err)
+		return nil, err
 	}
-	var info AmpStatus
-	err = json.Unmarshal(resp, &info)
+
+	return info, nil
+}
+
+// GetHardwareInfo returns a hardware info struct
+func (a *Amp60) GetHardwareInfo(ctx context.Context) (structs.HardwareInfo, error) {
+	var hwinfo structs.HardwareInfo
+
+	info, err := a.GetStatus(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal into AmpStatus: %w", err)
+		return hwinfo, fmt.Errorf("unable to get hardware info: %w", err)
 	}
-	return info, nil
+
+	hwinfo.ModelName = info.Model
+	hwinfo.FirmwareVersion = info.Firmware
+	hwinfo.SerialNumber = info.SerialNumber
+	hwinfo.NetworkInfo.MACAddress = info.MACAddress
+
+	// the network settings are extra, so still return what we have if they can't be read
+	network, err := a.GetNetworkSettings(ctx)
+	if err != nil {
+		log.L.Warnf("unable to get network settings for hardware info on %s: %s", a.Address, err)
+	} else {
+		hwinfo.NetworkInfo.IPAddress = network.IPAddress
+		hwinfo.NetworkInfo.Gateway = network.Gateway
+		if network.DNS != "" {
+			hwinfo.NetworkInfo.DNS = []string{network.DNS}
+		}
+	}
+
+	// fall back to the amp's own format if it couldn't be parsed
+	operatingTime := info.Uptime.String()
+	if info.Uptime == 0 && info.OperatingTime != "" {
+		operatingTime = info.OperatingTime
+	}
+
+	hwinfo.TimerInfo = map[string]string{
+		"operating_time": operatingTime,
+	}
+
+	return hwinfo, nil
 }
 
-// GetVolumeByBlock gets the current volume
+// GetVolumes gets the current volume
 func (a *Amp60) GetVolumes(ctx context.Context, blocks []string) (map[string]int, error) {
-	resp, err := a.sendReq(ctx, "deviceaudio_get")
+	vol, err := a.getRegisterInt(ctx, AmpRegVolume)
 	if err != nil {
 		return map[string]int{"": -1}, fmt.Errorf("unable to get volume: %w", err)
 	}
-	var info AmpAudio
-	var test map[string]interface{}
-	json.Unmarshal(resp, &test)
-	for key, value := range test {
-		log.L.Debug(key, value.(string))
+
+	return map[string]int{"": vol}, nil
+}
+
+// GetMutes gets the current muted status
+func (a *Amp60) GetMutes(ctx context.Context, blocks []string) (map[string]bool, error) {
+	muted, err := a.getRegisterBool(ctx, AmpRegMute)
+	if err != nil {
+		return map[string]bool{"": false}, fmt.Errorf("unable to get muted: %w", err)
+	}
+
+	return map[string]bool{"": muted}, nil
+}
+
+// SetVolume sets the volume on the amp
+func (a *Amp60) SetVolume(ctx context.Context, block string, volume int) error {
+	if err := a.SetRegister(ctx, AmpRegVolume, volume); err != nil {
+		return fmt.Errorf("unable to set volume: %w", err)
 	}
-	log.L.Debug("Testing our json: %v", test)
 
-	err = json.Unmarshal(resp, &info)
+	return nil
+}
+
+// SetMute sets the current muted status on the amp
+func (a *Amp60) SetMute(ctx context.Context, block string, muted bool) error {
+	if err := a.SetRegister(ctx, AmpRegMute, muted); err != nil {
+		return fmt.Errorf("unable to set muted: %w", err)
+	}
+
+	return nil
+}
+
+// GetBass gets the bass level on the amp, in dB
+func (a *Amp60) GetBass(ctx context.Context) (int, error) {
+	return a.getRegisterInt(ctx, AmpRegBass)
+}
+
+// SetBass sets the bass level on the amp, in dB
+func (a *Amp60) SetBass(ctx context.Context, level int) error {
+	return a.SetRegister(ctx, AmpRegBass, level)
+}
+
+// GetTreble gets the treble level on the amp, in dB
+func (a *Amp60) GetTreble(ctx context.Context) (int, error) {
+	return a.getRegisterInt(ctx, AmpRegTreble)
+}
+
+// SetTreble sets the treble level on the amp, in dB
+func (a *Amp60) SetTreble(ctx context.Context, level int) error {
+	return a.SetRegister(ctx, AmpRegTreble, level)
+}
+
+// GetBalance gets the left/right balance on the amp. negative values favor the left channel
+func (a *Amp60) GetBalance(ctx context.Context) (int, error) {
+	return a.getRegisterInt(ctx, AmpRegBalance)
+}
+
+// SetBalance sets the left/right balance on the amp. negative values favor the left channel
+func (a *Amp60) SetBalance(ctx context.Context, balance int) error {
+	return a.SetRegister(ctx, AmpRegBalance, balance)
+}
+
+// GetLoudness gets whether loudness compensation is turned on
+func (a *Amp60) GetLoudness(ctx context.Context) (bool, error) {
+	return a.getRegisterBool(ctx, AmpRegLoudness)
+}
+
+// SetLoudness turns loudness compensation on or off
+func (a *Amp60) SetLoudness(ctx context.Context, on bool) error {
+	return a.SetRegister(ctx, AmpRegLoudness, on)
+}
+
+// GetEQPreset gets the active equalizer preset
+func (a *Amp60) GetEQPreset(ctx context.Context) (EQPreset, error) {
+	val, err := a.getRegisterInt(ctx, AmpRegEQPreset)
 	if err != nil {
-		return map[string]int{"": -1}, fmt.Errorf("unable to unmarshal into AmpVolume in GetVolume: %w", err)
+		return EQFlat, err
 	}
-	toReturn, err := strconv.Atoi(info.Volume)
+
+	return EQPreset(val), nil
+}
+
+// SetEQPreset changes the active equalizer preset
+func (a *Amp60) SetEQPreset(ctx context.Context, preset EQPreset) error {
+	return a.SetRegister(ctx, AmpRegEQPreset, int(preset))
+}
+
+// GetInput gets the currently selected input on the amp
+func (a *Amp60) GetInput(ctx context.Context) (AmpInput, error) {
+	val, err := a.getRegisterInt(ctx, AmpRegInput)
 	if err != nil {
-		return map[string]int{"": -1}, fmt.Errorf("Volume is empty")
+		return AmpInputAnalog, err
 	}
-	return map[string]int{"": toReturn}, nil
+
+	return AmpInput(val), nil
 }
 
-// GetMutedByBlock gets the current muted status
-func (a *Amp60) GetMutes(ctx context.Context, blocks []string) (map[string]bool, error) {
-	resp, err := a.sendReq(ctx, "deviceaudio_get")
+// SetInput selects the active input on the amp
+func (a *Amp60) SetInput(ctx context.Context, input AmpInput) error {
+	return a.SetRegister(ctx, AmpRegInput, int(input))
+}
+
+// GetAutoSense gets whether the amp automatically switches to the input with a signal
+func (a *Amp60) GetAutoSense(ctx context.Context) (bool, error) {
+	return a.getRegisterBool(ctx, AmpRegAutoSense)
+}
+
+// SetAutoSense turns automatic input switching on or off
+func (a *Amp60) SetAutoSense(ctx context.Context, on bool) error {
+	return a.SetRegister(ctx, AmpRegAutoSense, on)
+}
+
+// GetSignals returns whether a signal is currently detected on each input
+func (a *Amp60) GetSignals(ctx context.Context) (map[AmpInput]bool, error) {
+	vals, err := a.readRegisters(ctx, AmpRegAnalogSignal, AmpRegDigitalSignal)
 	if err != nil {
+		return nil, fmt.Errorf("unable to get signals: %w", err)
+	}
 
-		return map[string]bool{"": false}, fmt.Errorf("unable to get muted: %w", err)
+	return map[AmpInput]bool{
+		AmpInputAnalog:  vals[AmpRegAnalogSignal.Key].(bool),
+		AmpInputDigital: vals[AmpRegDigitalSignal.Key].(bool),
+	}, nil
+}
+
+// GetStandbyTimers gets the auto standby and auto wake timers
+func (a *Amp60) GetStandbyTimers(ctx context.Context) (AmpStandbyTimers, error) {
+	var timers AmpStandbyTimers
+
+	vals, err := a.readRegisters(ctx, AmpRegStandbyTimeout, AmpRegWakeDelay)
+	if err != nil {
+		return timers, fmt.Errorf("unable to get standby timers: %w", err)
+	}
+
+	timers.AutoStandby = time.Duration(vals[AmpRegStandbyTimeout.Key].(int)) * time.Minute
+	timers.AutoWake = time.Duration(vals[AmpRegWakeDelay.Key].(int)) * time.Second
+	return timers, nil
+}
+
+// SetStandbyTimers sets the auto standby and auto wake timers
+func (a *Amp60) SetStandbyTimers(ctx context.Context, timers AmpStandbyTimers) error {
+	if timers.AutoStandby < 0 || timers.AutoStandby > ampMaxAutoStandby {
+		return fmt.Errorf("unable to set standby timers: auto standby must be between 0 and %v", ampMaxAutoStandby)
+	}
+
+	if timers.AutoWake < 0 || timers.AutoWake > ampMaxAutoWake {
+		return fmt.Errorf("unable to set standby timers: auto wake must be between 0 and %v", ampMaxAutoWake)
 	}
-	var info AmpAudio
-	err = json.Unmarshal(resp, &info)
+
+	err := a.SetRegister(ctx, AmpRegStandbyTimeout, int(timers.AutoStandby/time.Minute))
 	if err != nil {
-		return map[string]bool{"": false}, fmt.Errorf("unable to unmarshal into AmpVolume in GetMuted: %w", err)
+		return err
 	}
-	if info.Muted == "1" {
-		return map[string]bool{"": true}, nil
+
+	return a.SetRegister(ctx, AmpRegWakeDelay, int(timers.AutoWake/time.Second))
+}
+
+// GetStandby returns true if the amp is in standby
+func (a *Amp60) GetStandby(ctx context.Context) (bool, error) {
+	on, err := a.getRegisterBool(ctx, AmpRegPower)
+	if err != nil {
+		return false, err
 	}
-	return map[string]bool{"": false}, nil
+
+	return !on, nil
 }
 
-// SetVolumeByBlock sets the volume on the amp
-func (a *Amp60) SetVolume(ctx context.Context, block string, volume int) error {
-	_, err := a.sendReq(ctx, fmt.Sprintf("deviceaudio_set&608=%v", volume))
+// Standby puts the amp into standby
+func (a *Amp60) Standby(ctx context.Context) error {
+	return a.SetRegister(ctx, AmpRegPower, false)
+}
+
+// Wake wakes the amp up from standby
+func (a *Amp60) Wake(ctx context.Context) error {
+	return a.SetRegister(ctx, AmpRegPower, true)
+}
+
+// GetNetworkSettings gets the network settings on the amp
+func (a *Amp60) GetNetworkSettings(ctx context.Context) (AmpNetworkSettings, error) {
+	var settings AmpNetworkSettings
+
+	vals, err := a.readRegisters(ctx, AmpRegDHCP, AmpRegIP, AmpRegNetmask, AmpRegGateway, AmpRegDNS)
 	if err != nil {
-		return fmt.Errorf("unable to set volume: %w", err)
+		return settings, fmt.Errorf("unable to get network settings: %w", err)
+	}
+
+	settings.DHCP = vals[AmpRegDHCP.Key].(bool)
+	settings.IPAddress = vals[AmpRegIP.Key].(string)
+	settings.Netmask = vals[AmpRegNetmask.Key].(string)
+	settings.Gateway = vals[AmpRegGateway.Key].(string)
+	settings.DNS = vals[AmpRegDNS.Key].(string)
+	return settings, nil
+}
+
+// SetNetworkSettings changes the network settings on the amp. The static addresses are
+// ignored if DHCP is true. The amp moves to its new address as soon as the write succeeds,
+// so the login session is dropped and Address must be pointed at the new address.
+func (a *Amp60) SetNetworkSettings(ctx context.Context, settings AmpNetworkSettings) error {
+	if settings.DHCP {
+		if err := a.SetRegister(ctx, AmpRegDHCP, true); err != nil {
+			return fmt.Errorf("unable to set network settings: %w", err)
+		}
+
+		// the session was on the old address
+		a.invalidateSession()
+		return nil
+	}
+
+	var dns []string
+	if settings.DNS != "" {
+		dns = append(dns, settings.DNS)
+	}
+
+	if err := validateStaticIPv4(settings.IPAddress, settings.Netmask, settings.Gateway, dns...); err != nil {
+		return fmt.Errorf("unable to set network settings: %w", err)
+	}
+
+	// send everything at once so the amp doesn't end up half configured
+	regs := []AmpRegister{AmpRegDHCP, AmpRegIP, AmpRegNetmask, AmpRegGateway, AmpRegDNS}
+	vals := []interface{}{false, settings.IPAddress, settings.Netmask, settings.Gateway, settings.DNS}
+
+	if err := a.writeRegisters(ctx, regs, vals); err != nil {
+		return fmt.Errorf("unable to set network settings: %w", err)
 	}
+
+	// the session was on the old address
+	a.invalidateSession()
 	return nil
 }
 
-// SetMutedByBlock sets the current muted status on the amp
-func (a *Amp60) SetMute(ctx context.Context, block string, muted bool) error {
-	// open a connection with the dsp, set the muted status on block...
-	mutedString := "0"
-	if muted {
-		mutedString = "1"
+// SetPassword changes the admin password on the amp. Password is updated
+// once the amp accepts the change, so the driver keeps working.
+func (a *Amp60) SetPassword(ctx context.Context, password string) error {
+	if password == "" {
+		return fmt.Errorf("unable to set password: password cannot be empty")
 	}
-	_, err := a.sendReq(ctx, fmt.Sprintf("deviceaudio_set&609=%v", mutedString))
-	if err != nil {
-		return fmt.Errorf("unable to set muted: %w", err)
+
+	regs := []AmpRegister{AmpRegUsername, AmpRegPassword}
+	vals := []interface{}{a.Username, password}
+
+	if err := a.writeRegisters(ctx, regs, vals); err != nil {
+		return fmt.Errorf("unable to set password: %w", err)
 	}
+
+	a.sessionMu.Lock()
+	a.Password = password
+	a.loggedIn = false
+	a.sessionMu.Unlock()
+
 	return nil
 }
+
+// GetAudioDelays is not supported on the amp
+func (a *Amp60) GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error) {
+	return nil, &UnsupportedError{Feature: "audio delay"}
+}
+
+// SetAudioDelay is not supported on the amp
+func (a *Amp60) SetAudioDelay(ctx context.Context, output string, delay int) error {
+	return &UnsupportedError{Feature: "audio delay"}
+}
@@ -0,0 +1,64 @@
+package atlona
+
+import (
+	"testing"
+	"time"
+)
+
+var operatingTimeTests = []struct {
+	in       string
+	expected time.Duration
+	err      bool
+}{
+	{in: "12:34:56", expected: 12*time.Hour + 34*time.Minute + 56*time.Second},
+	{in: "1234:05", expected: 1234*time.Hour + 5*time.Minute},
+	{in: "00d 05h 12m", expected: 5*time.Hour + 12*time.Minute},
+	{in: "3d 04h 12m 30s", expected: 3*24*time.Hour + 4*time.Hour + 12*time.Minute + 30*time.Second},
+	{in: "1520", expected: 1520 * time.Hour},
+	{in: "5 weeks", err: true},
+	{in: "12:xx", err: true},
+	{in: "1:2:3:4", err: true},
+	{in: "", err: true},
+}
+
+func TestParseOperatingTime(t *testing.T) {
+	for _, tt := range operatingTimeTests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := parseOperatingTime(tt.in)
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error, got %v", d)
+			case !tt.err && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case d != tt.expected:
+				t.Errorf("got %v, expected %v", d, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedactAmpURL(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{
+			in:       "http://10.0.0.5/action=compare&701=admin&702=p%40ss&r=123",
+			expected: "http://10.0.0.5/action=compare&701=REDACTED&702=REDACTED&r=123",
+		},
+		{
+			in:       "http://10.0.0.5/action=setvalue&701=admin&702=new",
+			expected: "http://10.0.0.5/action=setvalue&701=REDACTED&702=REDACTED",
+		},
+		{
+			in:       "http://10.0.0.5/action=getvalue&608=&609=&r=123",
+			expected: "http://10.0.0.5/action=getvalue&608=&609=&r=123",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := redactAmpURL(tt.in); got != tt.expected {
+			t.Errorf("got %q, expected %q", got, tt.expected)
+		}
+	}
+}
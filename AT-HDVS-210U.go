@@ -2,11 +2,9 @@ package atlona
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
+	"sync"
 
 	"github.com/byuoitav/common/structs"
 )
@@ -15,6 +13,9 @@ type AtlonaVideoSwitcher2x1 struct {
 	Username string
 	Password string
 	Address  string
+
+	mu sync.Mutex
+	aj *ajClient
 }
 
 type wallPlateStruct struct {
@@ -28,23 +29,51 @@ type wallPlateStruct struct {
 	HDCPSet   []int  `json:"HDCPSet"`
 }
 
-func (vs *AtlonaVideoSwitcher2x1) make2x1request(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error when creting the request: %w", err)
-	}
-	req = req.WithContext(ctx)
-	res, gerr := http.DefaultClient.Do(req)
-	if gerr != nil {
-		return nil, fmt.Errorf("error when making call: %w", gerr)
-	}
-	defer res.Body.Close()
+// wallPlateNetwork is the response from the wall plate for the network page
+type wallPlateNetwork struct {
+	IPAddress  string `json:"ip"`
+	Netmask    string `json:"mask"`
+	Gateway    string `json:"gw"`
+	MACAddress string `json:"mac"`
+	DHCP       int    `json:"dhcp"`
+	LoginUr    int    `json:"login_ur"`
+}
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error when making call: %w", gerr)
+// wallPlateSystem is the response from the wall plate for the info page. only the system section is read
+type wallPlateSystem struct {
+	SystemInfo SystemInfo `json:"info_val1"`
+	LoginUr    int        `json:"login_ur"`
+}
+
+// WallPlateInfo is the system, network, and HDCP info for a wall plate
+type WallPlateInfo struct {
+	Model      string `json:"model"`
+	Firmware   string `json:"firmware"`
+	IPAddress  string `json:"ip_address"`
+	Netmask    string `json:"netmask"`
+	Gateway    string `json:"gateway"`
+	MACAddress string `json:"mac_address"`
+	DHCP       bool   `json:"dhcp"`
+
+	// HDCP is whether HDCP is enabled on each input, in input order
+	HDCP []bool `json:"hdcp"`
+}
+
+// client returns the aj.html client for the switcher's current address and credentials. if
+// any of them have changed since the last call, a new client (and session) is created
+func (vs *AtlonaVideoSwitcher2x1) client() *ajClient {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.aj == nil || vs.aj.Address != vs.Address || vs.aj.Username != vs.Username || vs.aj.Password != vs.Password {
+		vs.aj = &ajClient{
+			Address:  vs.Address,
+			Username: vs.Username,
+			Password: vs.Password,
+		}
 	}
-	return body, nil
+
+	return vs.aj
 }
 
 // GetAudioVideoInputs .
@@ -52,14 +81,9 @@ func (vs *AtlonaVideoSwitcher2x1) GetAudioVideoInputs(ctx context.Context) (map[
 	toReturn := make(map[string]string)
 
 	var resp wallPlateStruct
-	url := fmt.Sprintf("http://%s/aj.html?a=avs", vs.Address)
-	body, gerr := vs.make2x1request(ctx, url)
-	if gerr != nil {
-		return toReturn, fmt.Errorf("An error occured while making the call: %w", gerr)
-	}
-	err := json.Unmarshal([]byte(body), &resp) // here!
+	err := vs.client().getPage(ctx, avSettingsPage, &resp)
 	if err != nil {
-		return toReturn, fmt.Errorf("error when unmarshalling the response: %w", err)
+		return toReturn, fmt.Errorf("unable to get input: %w", err)
 	}
 
 	in := strconv.Itoa(resp.Inp)
@@ -77,22 +101,89 @@ func (vs *AtlonaVideoSwitcher2x1) SetAudioVideoInput(ctx context.Context, output
 	if intInput != 1 && intInput != 2 {
 		return fmt.Errorf("Invalid Input, the input you sent was %v the valid inputs are 1 or 2", intInput)
 	}
-	url := fmt.Sprintf("http://%s/aj.html?a=command&cmd=x%sAVx1", vs.Address, input)
-	_, gerr := vs.make2x1request(ctx, url)
-	if gerr != nil {
-		return fmt.Errorf("An error occured while making the call: %w", gerr)
+
+	cmd, err := switchAVCommand(intInput, 1)
+	if err != nil {
+		return fmt.Errorf("unable to switch input: %w", err)
 	}
+
+	err = vs.client().sendCommand(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("unable to switch input: %w", err)
+	}
+
 	return nil
 }
 
-//GetHardwareInfo .
+// GetWallPlateInfo returns the system, network, and HDCP info from the wall plate
+func (vs *AtlonaVideoSwitcher2x1) GetWallPlateInfo(ctx context.Context) (WallPlateInfo, error) {
+	var info WallPlateInfo
+
+	var sys wallPlateSystem
+	if err := vs.client().getPage(ctx, infoPage, &sys); err != nil {
+		return info, err
+	}
+
+	info.Model = sys.SystemInfo.Model
+	info.Firmware = sys.SystemInfo.Firmware
+
+	var network wallPlateNetwork
+	if err := vs.client().getPage(ctx, networkPage, &network); err != nil {
+		return info, err
+	}
+
+	info.IPAddress = network.IPAddress
+	info.Netmask = network.Netmask
+	info.Gateway = network.Gateway
+	info.MACAddress = network.MACAddress
+	info.DHCP = network.DHCP == 1
+
+	var avs wallPlateStruct
+	if err := vs.client().getPage(ctx, avSettingsPage, &avs); err != nil {
+		return info, err
+	}
+
+	for _, hdcp := range avs.HDCPSet {
+		info.HDCP = append(info.HDCP, hdcp == 1)
+	}
+
+	return info, nil
+}
+
+// GetHardwareInfo returns a hardware info struct
 func (vs *AtlonaVideoSwitcher2x1) GetHardwareInfo(ctx context.Context) (structs.HardwareInfo, error) {
-	var resp structs.HardwareInfo
-	return resp, nil
+	var hwinfo structs.HardwareInfo
+
+	info, err := vs.GetWallPlateInfo(ctx)
+	if err != nil {
+		return hwinfo, fmt.Errorf("unable to get hardware info: %w", err)
+	}
+
+	hwinfo.ModelName = info.Model
+	hwinfo.FirmwareVersion = info.Firmware
+	hwinfo.NetworkInfo.IPAddress = info.IPAddress
+	hwinfo.NetworkInfo.MACAddress = info.MACAddress
+	hwinfo.NetworkInfo.Gateway = info.Gateway
+
+	return hwinfo, nil
 }
 
-//GetInfo .
+// GetInfo returns a WallPlateInfo
 func (vs *AtlonaVideoSwitcher2x1) GetInfo(ctx context.Context) (interface{}, error) {
-	var info interface{}
-	return info, fmt.Errorf("not currently implemented")
+	info, err := vs.GetWallPlateInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get info: %w", err)
+	}
+
+	return info, nil
+}
+
+// GetAudioDelays is not supported on the wall plate
+func (vs *AtlonaVideoSwitcher2x1) GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error) {
+	return nil, &UnsupportedError{Feature: "audio delay"}
+}
+
+// SetAudioDelay is not supported on the wall plate
+func (vs *AtlonaVideoSwitcher2x1) SetAudioDelay(ctx context.Context, output string, delay int) error {
+	return &UnsupportedError{Feature: "audio delay"}
 }
@@ -0,0 +1,135 @@
+package atlona
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveWallPlate starts a fake wall plate that answers the info, net, and avs pages with the given bodies
+func serveWallPlate(pages map[ajPage]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[ajPage(r.URL.Query().Get("a"))]
+		if r.URL.Path != "/aj.html" || !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+}
+
+var wallPlateInfoTests = []struct {
+	name  string
+	pages map[ajPage]string
+	info  WallPlateInfo
+	err   bool
+}{
+	{
+		name: "all pages",
+		pages: map[ajPage]string{
+			infoPage:       `{"info_val1":["AT-HDVS-210U-TX","1.3.4"],"login_ur":1}`,
+			networkPage:    `{"ip":"10.5.34.22","mask":"255.255.255.0","gw":"10.5.34.1","mac":"b8:98:b0:02:11:3c","dhcp":1,"login_ur":1}`,
+			avSettingsPage: `{"login_ur":1,"inp":1,"HDCPSet":[1,0]}`,
+		},
+		info: WallPlateInfo{
+			Model:      "AT-HDVS-210U-TX",
+			Firmware:   "1.3.4",
+			IPAddress:  "10.5.34.22",
+			Netmask:    "255.255.255.0",
+			Gateway:    "10.5.34.1",
+			MACAddress: "b8:98:b0:02:11:3c",
+			DHCP:       true,
+			HDCP:       []bool{true, false},
+		},
+	},
+	{
+		name: "video info is ignored",
+		pages: map[ajPage]string{
+			infoPage:       `{"info_val1":["AT-HDVS-210U-TX","1.3.4"],"info_val2":{"unexpected":true},"login_ur":1}`,
+			networkPage:    `{"ip":"10.5.34.22","mask":"255.255.255.0","gw":"10.5.34.1","mac":"b8:98:b0:02:11:3c","dhcp":0,"login_ur":1}`,
+			avSettingsPage: `{"login_ur":1,"inp":1,"HDCPSet":[]}`,
+		},
+		info: WallPlateInfo{
+			Model:      "AT-HDVS-210U-TX",
+			Firmware:   "1.3.4",
+			IPAddress:  "10.5.34.22",
+			Netmask:    "255.255.255.0",
+			Gateway:    "10.5.34.1",
+			MACAddress: "b8:98:b0:02:11:3c",
+		},
+	},
+	{
+		name: "no network page",
+		pages: map[ajPage]string{
+			infoPage:       `{"info_val1":["AT-HDVS-210U-TX","1.3.4"],"login_ur":1}`,
+			avSettingsPage: `{"login_ur":1,"inp":1,"HDCPSet":[1,1]}`,
+		},
+		err: true,
+	},
+}
+
+func TestGetWallPlateInfo(t *testing.T) {
+	for _, tt := range wallPlateInfoTests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveWallPlate(tt.pages)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			vs := &AtlonaVideoSwitcher2x1{Address: strings.TrimPrefix(server.URL, "http://")}
+
+			info, err := vs.GetWallPlateInfo(ctx)
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error, got %+v", info)
+			case tt.err:
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(info, tt.info) {
+				t.Errorf("got %+v, expected %+v", info, tt.info)
+			}
+		})
+	}
+}
+
+func TestGetHardwareInfo2x1(t *testing.T) {
+	server := serveWallPlate(wallPlateInfoTests[0].pages)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vs := &AtlonaVideoSwitcher2x1{Address: strings.TrimPrefix(server.URL, "http://")}
+
+	hwinfo, err := vs.GetHardwareInfo(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := wallPlateInfoTests[0].info
+	if hwinfo.ModelName != expected.Model || hwinfo.FirmwareVersion != expected.Firmware {
+		t.Errorf("got model %q firmware %v", hwinfo.ModelName, hwinfo.FirmwareVersion)
+	}
+
+	if hwinfo.NetworkInfo.IPAddress != expected.IPAddress || hwinfo.NetworkInfo.Gateway != expected.Gateway || hwinfo.NetworkInfo.MACAddress != expected.MACAddress {
+		t.Errorf("got network info %+v", hwinfo.NetworkInfo)
+	}
+
+	info, err := vs.GetInfo(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("got info %+v, expected %+v", info, expected)
+	}
+}
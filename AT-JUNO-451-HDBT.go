@@ -4,22 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/byuoitav/common/log"
 	"github.com/byuoitav/common/structs"
 )
 
-const (
-	avSettingsPage = "avs"
-	infoPage       = "info"
-)
-
 type AtlonaVideoSwitcher4x1 struct {
 	Username string
 	Password string
 	Address  string
+
+	mu sync.Mutex
+	aj *ajClient
 }
 
 // AVSettings is the response from the switcher for the av settings page
@@ -30,102 +31,388 @@ type AVSettings struct {
 	Toslink                int   `json:"Toslink"`
 	AutoSwitch             int   `json:"asw"`
 	Input                  int   `json:"inp"`
+	AudioDelay             *int  `json:"ADelay"`
 	LoggedIn               int   `json:"login_ur"`
 }
 
 // Info is the response from the switcher for the info page
 type Info struct {
-	SystemInfo []string      `json:"info_val1"`
-	VideoInfo  []interface{} `json:"info_val2"`
-	LoggedIn   int           `json:"login_ur"`
+	SystemInfo SystemInfo       `json:"info_val1"`
+	VideoInfo  []InputVideoInfo `json:"info_val2"`
+	LoggedIn   int              `json:"login_ur"`
+}
+
+// UnmarshalJSON decodes the info page. The video info isn't needed to read the system info, so if
+// info_val2 isn't a list, it is logged and left empty instead of failing the whole page
+func (i *Info) UnmarshalJSON(b []byte) error {
+	var page struct {
+		SystemInfo SystemInfo      `json:"info_val1"`
+		VideoInfo  json.RawMessage `json:"info_val2"`
+		LoggedIn   int             `json:"login_ur"`
+	}
+
+	if err := json.Unmarshal(b, &page); err != nil {
+		return fmt.Errorf("unable to decode info page: %w", err)
+	}
+
+	*i = Info{
+		SystemInfo: page.SystemInfo,
+		LoggedIn:   page.LoggedIn,
+	}
+
+	if len(page.VideoInfo) > 0 {
+		if err := json.Unmarshal(page.VideoInfo, &i.VideoInfo); err != nil {
+			log.L.Warnf("unable to decode video info %s: %s", page.VideoInfo, err)
+			i.VideoInfo = nil
+		}
+	}
+
+	return nil
+}
+
+// SystemInfo is the system section of the info page
+type SystemInfo struct {
+	Model    string   `json:"model"`
+	Firmware string   `json:"firmware"`
+	Values   []string `json:"values"`
+}
+
+// UnmarshalJSON decodes the list of values the switcher sends into a SystemInfo
+func (s *SystemInfo) UnmarshalJSON(b []byte) error {
+	var vals []interface{}
+	if err := json.Unmarshal(b, &vals); err != nil {
+		return fmt.Errorf("unable to decode system info: %w", err)
+	}
+
+	s.Values = make([]string, 0, len(vals))
+	for _, val := range vals {
+		s.Values = append(s.Values, strings.TrimSpace(fmt.Sprintf("%v", val)))
+	}
+
+	if len(s.Values) >= 1 {
+		s.Model = s.Values[0]
+	}
+
+	if len(s.Values) >= 2 {
+		s.Firmware = s.Values[1]
+	}
+
+	return nil
+}
+
+// InputVideoInfo is the video signal detected on an input
+type InputVideoInfo struct {
+	Signal      bool    `json:"signal"`
+	Resolution  string  `json:"resolution,omitempty"`
+	Interlaced  bool    `json:"interlaced,omitempty"`
+	RefreshRate float64 `json:"refresh_rate,omitempty"`
+	ColorSpace  string  `json:"color_space,omitempty"`
+	HDCPVersion string  `json:"hdcp_version,omitempty"`
+
+	// Raw is the entry as the switcher sent it, if it wasn't in a format that could be read
+	Raw json.RawMessage `json:"raw,omitempty"`
+}
+
+var (
+	// the refresh rate is only read from right after the scan type (1080p60), after an @, or before Hz
+	videoResolutionRegex = regexp.MustCompile(`(?i)(\d{3,4})\s*x\s*(\d{3,4})(?:([pi])(\d+(?:\.\d+)?)?)?(?:\s*@\s*(\d+(?:\.\d+)?)\s*(?:hz)?|\s*(\d+(?:\.\d+)?)\s*hz)?`)
+	videoRefreshRegex    = regexp.MustCompile(`(?i)^(?:@\s*(\d+(?:\.\d+)?)\s*(?:hz)?|(\d+(?:\.\d+)?)\s*hz)$`)
+	videoColorSpaceRegex = regexp.MustCompile(`(?i)\b(?:rgb|ycbcr|yuv|ycc)(?:\s*(?:4:?4:?4|4:?2:?2|4:?2:?0))?\b`)
+	videoHDCPRegex       = regexp.MustCompile(`(?i)hdcp\s*v?\s*(\d\.\d)`)
+)
+
+// UnmarshalJSON decodes an input's entry on the info page. Depending on firmware, the entry is either a
+// list of values or a single string (ie, "1920x1080p60 RGB HDCP1.4"). The values are identified by what
+// they look like rather than their position. Any other shape is logged and kept in Raw, with no signal.
+func (v *InputVideoInfo) UnmarshalJSON(b []byte) error {
+	var fields []string
+
+	var list []interface{}
+	var str string
+
+	switch {
+	case json.Unmarshal(b, &list) == nil:
+		for _, val := range list {
+			fields = append(fields, strings.TrimSpace(fmt.Sprintf("%v", val)))
+		}
+	case json.Unmarshal(b, &str) == nil:
+		fields = append(fields, str)
+
+		// also look at each word, so that the color space and refresh rate can be picked out
+		fields = append(fields, strings.Fields(str)...)
+	default:
+		log.L.Warnf("unknown input video info format: %s", b)
+		*v = InputVideoInfo{Raw: append(json.RawMessage(nil), b...)}
+		return nil
+	}
+
+	*v = parseInputVideoInfo(fields)
+	return nil
+}
+
+func parseInputVideoInfo(fields []string) InputVideoInfo {
+	var info InputVideoInfo
+
+	for _, field := range fields {
+		if match := videoHDCPRegex.FindStringSubmatch(field); match != nil && info.HDCPVersion == "" {
+			info.HDCPVersion = match[1]
+		}
+
+		if match := videoResolutionRegex.FindStringSubmatch(field); match != nil && info.Resolution == "" {
+			info.Resolution = match[1] + "x" + match[2]
+			info.Interlaced = strings.EqualFold(match[3], "i")
+
+			if info.RefreshRate == 0 {
+				info.RefreshRate = parseRefreshRate(match[4:]...)
+			}
+		}
+
+		if match := videoRefreshRegex.FindStringSubmatch(field); match != nil && info.RefreshRate == 0 {
+			info.RefreshRate = parseRefreshRate(match[1:]...)
+		}
+
+		if match := videoColorSpaceRegex.FindString(field); match != "" && info.ColorSpace == "" {
+			info.ColorSpace = match
+		}
+	}
+
+	info.Signal = info.Resolution != "" && info.Resolution != "0x0"
+	return info
+}
+
+// parseRefreshRate returns the first of the matched refresh rates that was found
+func parseRefreshRate(matches ...string) float64 {
+	for _, match := range matches {
+		if rate, err := strconv.ParseFloat(match, 64); err == nil {
+			return rate
+		}
+	}
+
+	return 0
 }
 
 // SystemSettings .
 type SystemSettings struct {
 }
 
-func getPage(ctx context.Context, address, page string, structToFill interface{}) error {
-	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s/aj.html?a=%s", address, page), nil)
-	if err != nil {
-		return fmt.Errorf("unable to get page %s on %s", page, address)
+// client returns the aj.html client for the switcher's current address and credentials. if
+// any of them have changed since the last call, a new client (and session) is created
+func (vs *AtlonaVideoSwitcher4x1) client() *ajClient {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.aj == nil || vs.aj.Address != vs.Address || vs.aj.Username != vs.Username || vs.aj.Password != vs.Password {
+		vs.aj = &ajClient{
+			Address:  vs.Address,
+			Username: vs.Username,
+			Password: vs.Password,
+		}
 	}
 
-	req = req.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(req)
+	return vs.aj
+}
+
+// NetworkSettings is the network configuration scraped from the switcher's web page
+type NetworkSettings struct {
+	IPAddress  string
+	Netmask    string
+	Gateway    string
+	DNS        []string
+	MACAddress string
+	DHCP       bool
+}
+
+// NetworkInfo converts the settings into a structs.NetworkInfo
+func (n NetworkSettings) NetworkInfo() structs.NetworkInfo {
+	return structs.NetworkInfo{
+		IPAddress:  n.IPAddress,
+		MACAddress: n.MACAddress,
+		Gateway:    n.Gateway,
+		DNS:        n.DNS,
+	}
+}
+
+func (vs *AtlonaVideoSwitcher4x1) getNetworkSettings(ctx context.Context) (NetworkSettings, error) {
+	// get the ip info (bleh, gross. it's in the html)
+	b, err := vs.client().getRoot(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to get page %s on %s", page, address)
+		return NetworkSettings{}, fmt.Errorf("unable to get network settings: %w", err)
 	}
-	defer resp.Body.Close()
 
-	b, err := ioutil.ReadAll(resp.Body)
+	info, err := parseNetworkSettings(string(b))
 	if err != nil {
-		return fmt.Errorf("unable to get page %s on %s", page, address)
+		return info, fmt.Errorf("unable to get network settings from %s: %w", vs.Address, err)
 	}
 
-	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("unable to get page %s on %s - %v response recevied. body: %s", page, address, resp.StatusCode, b)
+	return info, nil
+}
+
+// the same settings show up differently depending on the firmware version: as a
+// label/value table, as form inputs, or as a javascript object on the page.
+var (
+	ipField      = htmlField{names: []string{"ip", "ipaddr", "ip_addr", "ipaddress"}, labels: []string{"IP Address", "IP"}}
+	netmaskField = htmlField{names: []string{"mask", "netmask", "subnet"}, labels: []string{"Subnet Mask", "Netmask", "Mask"}}
+	gatewayField = htmlField{names: []string{"gw", "gateway"}, labels: []string{"Gateway", "Default Gateway"}}
+	macField     = htmlField{names: []string{"mac", "macaddr", "mac_addr"}, labels: []string{"MAC Address", "MAC"}}
+	dhcpField    = htmlField{names: []string{"dhcp", "ipmode", "ip_mode"}, labels: []string{"IP Mode", "DHCP"}}
+	dnsFields    = []htmlField{
+		{names: []string{"dns", "dns1"}, labels: []string{"DNS Server", "Primary DNS", "DNS"}},
+		{names: []string{"dns2"}, labels: []string{"Secondary DNS"}},
 	}
+)
 
-	err = json.Unmarshal(b, structToFill)
-	if err != nil {
-		return fmt.Errorf("unable to get page %s on %s", page, address)
+// parseNetworkSettings scrapes the network settings out of the switcher's root page
+func parseNetworkSettings(html string) (NetworkSettings, error) {
+	var info NetworkSettings
+
+	info.IPAddress = ipField.find(html)
+	if net.ParseIP(info.IPAddress) == nil {
+		return info, fmt.Errorf("unable to find ip address in page")
 	}
 
-	return nil
+	info.Netmask = netmaskField.find(html)
+	info.Gateway = gatewayField.find(html)
+
+	info.MACAddress = macField.find(html)
+	if mac, err := net.ParseMAC(info.MACAddress); err == nil {
+		info.MACAddress = mac.String()
+	}
+
+	switch strings.ToLower(dhcpField.find(html)) {
+	case "1", "dhcp", "on", "true", "yes", "auto", "enable", "enabled":
+		info.DHCP = true
+	}
+
+	for _, field := range dnsFields {
+		dns := field.find(html)
+		if ip := net.ParseIP(dns); ip != nil && !ip.IsUnspecified() {
+			info.DNS = append(info.DNS, dns)
+		}
+	}
+
+	return info, nil
 }
 
-func sendCommand(ctx context.Context, address, command string) error {
-	req, err := http.NewRequest("GET", fmt.Sprintf("http://%v/aj.html?a=command&cmd=%s", address, command), nil)
-	if err != nil {
-		return fmt.Errorf("unable to send command '%s' to %s", command, address)
+// htmlField is a value on a web page, identified by its form/script names or its label
+type htmlField struct {
+	names  []string
+	labels []string
+}
+
+var (
+	htmlInputRegex  = regexp.MustCompile(`(?is)<input\b[^>]*>`)
+	htmlValueRegex  = regexp.MustCompile(`(?is)\bvalue\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlOptionRegex = regexp.MustCompile(`(?is)<option\b([^>]*)>(.*?)</option>`)
+	htmlTagRegex    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// find returns the first value found for the field, or an empty string
+func (f htmlField) find(html string) string {
+	for _, name := range f.names {
+		if val := findInputValue(html, name); val != "" {
+			return val
+		}
+
+		if val := findSelectValue(html, name); val != "" {
+			return val
+		}
+
+		if val := findScriptValue(html, name); val != "" {
+			return val
+		}
 	}
 
-	req = req.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("unable to send command '%s' to %s", command, address)
+	for _, label := range f.labels {
+		if val := findLabelValue(html, label); val != "" {
+			return val
+		}
 	}
-	defer resp.Body.Close()
 
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("unable to send command '%s' to %s", command, address)
+	return ""
+}
+
+func htmlNameRegex(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(?:name|id)\s*=\s*["']?` + regexp.QuoteMeta(name) + `(?:["'\s/>]|$)`)
+}
+
+func htmlAttrValue(tag string) string {
+	match := htmlValueRegex.FindStringSubmatch(tag)
+	if match == nil {
+		return ""
 	}
 
-	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("unable to send command '%s' to %s - %v response received. body: %s", command, address, resp.StatusCode, b)
+	return strings.TrimSpace(match[1] + match[2] + match[3])
+}
+
+// findInputValue finds <input name="name" value="...">. radio buttons only count if they are checked
+func findInputValue(html, name string) string {
+	nameRegex := htmlNameRegex(name)
+
+	for _, tag := range htmlInputRegex.FindAllString(html, -1) {
+		if !nameRegex.MatchString(tag) {
+			continue
+		}
+
+		lower := strings.ToLower(tag)
+		if strings.Contains(lower, "radio") && !strings.Contains(lower, "checked") {
+			continue
+		}
+
+		if val := htmlAttrValue(tag); val != "" {
+			return val
+		}
 	}
 
-	return nil
+	return ""
 }
 
-// TODO finish this :)
-func getNetworkSettings(ctx context.Context, address string) (structs.NetworkInfo, error) {
-	var info structs.NetworkInfo
+// findSelectValue finds the selected option in <select name="name">
+func findSelectValue(html, name string) string {
+	selectRegex := regexp.MustCompile(`(?is)<select\b[^>]*\b(?:name|id)\s*=\s*["']?` + regexp.QuoteMeta(name) + `["']?[^>]*>(.*?)</select>`)
 
-	// get the ip info (bleh, gross. it's in the html)
-	req, gerr := http.NewRequest("GET", fmt.Sprintf("http://%v", address), nil)
-	if gerr != nil {
-		return info, fmt.Errorf("unable to get network settings from %s:%w", address, gerr)
+	match := selectRegex.FindStringSubmatch(html)
+	if match == nil {
+		return ""
 	}
 
-	req = req.WithContext(ctx)
-	resp, gerr := http.DefaultClient.Do(req)
-	if gerr != nil {
-		return info, fmt.Errorf("unable to get network settings from %s:%w", address, gerr)
+	for _, option := range htmlOptionRegex.FindAllStringSubmatch(match[1], -1) {
+		if !strings.Contains(strings.ToLower(option[1]), "selected") {
+			continue
+		}
+
+		if val := htmlAttrValue(option[1]); val != "" {
+			return val
+		}
+
+		return strings.TrimSpace(htmlTagRegex.ReplaceAllString(option[2], ""))
 	}
-	defer resp.Body.Close()
 
-	b, gerr := ioutil.ReadAll(resp.Body)
-	if gerr != nil {
-		return info, fmt.Errorf("unable to get network settings from %s:%w", address, gerr)
+	return ""
+}
+
+// findScriptValue finds name: "value" or name = "value" in a script on the page
+func findScriptValue(html, name string) string {
+	scriptRegex := regexp.MustCompile(`(?i)(?:^|[^\w-])["']?` + regexp.QuoteMeta(name) + `["']?\s*[:=]\s*["']?([^"',;}\s<>]*)`)
+
+	for _, match := range scriptRegex.FindAllStringSubmatch(html, -1) {
+		if match[1] != "" {
+			return match[1]
+		}
 	}
 
-	if resp.StatusCode/100 != 2 {
-		return info, fmt.Errorf("unable to get network settings from %s - %v response received. body: %s", address, resp.StatusCode, b)
+	return ""
+}
+
+// findLabelValue finds <td>label:</td><td>value</td>
+func findLabelValue(html, label string) string {
+	labelRegex := regexp.MustCompile(`(?is)>\s*` + regexp.QuoteMeta(label) + `\s*:?\s*</t[dh]>\s*<td[^>]*>(.*?)</td>`)
+
+	match := labelRegex.FindStringSubmatch(html)
+	if match == nil {
+		return ""
 	}
 
-	return info, nil
+	return strings.TrimSpace(htmlTagRegex.ReplaceAllString(match[1], ""))
 }
 
 // GetAudioVideoInputs returns the current input
@@ -133,7 +420,7 @@ func (vs *AtlonaVideoSwitcher4x1) GetAudioVideoInputs(ctx context.Context) (map[
 	toReturn := make(map[string]string)
 
 	var settings AVSettings
-	err := getPage(ctx, vs.Address, avSettingsPage, &settings)
+	err := vs.client().getPage(ctx, avSettingsPage, &settings)
 	if err != nil {
 		return toReturn, fmt.Errorf("unable to get input: %w", err)
 	}
@@ -147,20 +434,22 @@ func (vs *AtlonaVideoSwitcher4x1) GetHardwareInfo(ctx context.Context) (structs.
 	var hwinfo structs.HardwareInfo
 
 	var info Info
-	err := getPage(ctx, vs.Address, infoPage, &info)
+	err := vs.client().getPage(ctx, infoPage, &info)
 	if err != nil {
 		return hwinfo, fmt.Errorf("unable to get hardware info: %w", err)
 	}
 
-	// fill in the hwinfo
-	if len(info.SystemInfo) >= 1 {
-		hwinfo.ModelName = info.SystemInfo[0]
-	}
+	hwinfo.ModelName = info.SystemInfo.Model
+	hwinfo.FirmwareVersion = info.SystemInfo.Firmware
 
-	if len(info.SystemInfo) >= 2 {
-		hwinfo.FirmwareVersion = info.SystemInfo[1]
+	// the network settings are scraped from the web page, so still return what we have if they can't be read
+	network, err := vs.getNetworkSettings(ctx)
+	if err != nil {
+		log.L.Warnf("unable to get network settings for hardware info on %s: %s", vs.Address, err)
+		return hwinfo, nil
 	}
 
+	hwinfo.NetworkInfo = network.NetworkInfo()
 	return hwinfo, nil
 }
 
@@ -182,7 +471,7 @@ func (vs *AtlonaVideoSwitcher4x1) SetAudioVideoInput(ctx context.Context, output
 
 	// validate that input/output are valid numbers
 	var settings AVSettings
-	err := getPage(ctx, vs.Address, avSettingsPage, &settings)
+	err := vs.client().getPage(ctx, avSettingsPage, &settings)
 	if err != nil {
 		return fmt.Errorf("unable to switch input: %w", err)
 	}
@@ -195,7 +484,12 @@ func (vs *AtlonaVideoSwitcher4x1) SetAudioVideoInput(ctx context.Context, output
 		return fmt.Errorf("unable to switch input on %s - output %s is invalid", vs.Address, output)
 	}
 
-	err = sendCommand(ctx, vs.Address, fmt.Sprintf("x%vAVx%v", in, out))
+	cmd, err := switchAVCommand(in, out)
+	if err != nil {
+		return fmt.Errorf("unable to switch input: %w", err)
+	}
+
+	err = vs.client().sendCommand(ctx, cmd)
 	if err != nil {
 		return fmt.Errorf("unable to switch input: %w", err)
 	}
@@ -203,8 +497,59 @@ func (vs *AtlonaVideoSwitcher4x1) SetAudioVideoInput(ctx context.Context, output
 	return nil
 }
 
-//GetInfo .
+// GetInfo returns the switcher's Info, including the video signal on each input
 func (vs *AtlonaVideoSwitcher4x1) GetInfo(ctx context.Context) (interface{}, error) {
-	var info interface{}
-	return info, fmt.Errorf("not currently implemented")
+	var info Info
+	err := vs.client().getPage(ctx, infoPage, &info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get info: %w", err)
+	}
+
+	return info, nil
+}
+
+// junoMaxAudioDelay is the longest audio delay the switcher accepts, in milliseconds
+const junoMaxAudioDelay = 500
+
+// GetAudioDelays returns the audio delay on the output, in milliseconds
+func (vs *AtlonaVideoSwitcher4x1) GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error) {
+	toReturn := make(map[string]int)
+
+	var settings AVSettings
+	err := vs.client().getPage(ctx, avSettingsPage, &settings)
+	if err != nil {
+		return toReturn, fmt.Errorf("unable to get audio delay: %w", err)
+	}
+
+	if settings.AudioDelay == nil {
+		return toReturn, &UnsupportedError{Feature: "audio delay"}
+	}
+
+	for _, block := range blocks {
+		if block != "" && block != "0" {
+			return toReturn, &InvalidOutputError{Output: block, Valid: []string{"0"}}
+		}
+
+		toReturn[block] = *settings.AudioDelay
+	}
+
+	return toReturn, nil
+}
+
+// SetAudioDelay sets the audio delay on the output, in milliseconds
+func (vs *AtlonaVideoSwitcher4x1) SetAudioDelay(ctx context.Context, output string, delay int) error {
+	if output != "" && output != "0" {
+		return &InvalidOutputError{Output: output, Valid: []string{"0"}}
+	}
+
+	if delay < 0 || delay > junoMaxAudioDelay {
+		return fmt.Errorf("unable to set audio delay: delay must be between 0 and %v", junoMaxAudioDelay)
+	}
+
+	err := vs.client().sendCommand(ctx, audioDelayCommand(delay))
+	if err != nil {
+		return fmt.Errorf("unable to set audio delay: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,326 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var networkSettingsTests = []struct {
+	fixture  string
+	settings NetworkSettings
+}{
+	{
+		fixture: "fw1.10.html",
+		settings: NetworkSettings{
+			IPAddress:  "10.13.34.51",
+			Netmask:    "255.255.248.0",
+			Gateway:    "10.13.32.1",
+			DNS:        []string{"10.8.0.26"},
+			MACAddress: "b8:98:b0:03:7a:1c",
+			DHCP:       true,
+		},
+	},
+	{
+		fixture: "fw1.21.html",
+		settings: NetworkSettings{
+			IPAddress:  "10.5.34.21",
+			Netmask:    "255.255.255.0",
+			Gateway:    "10.5.34.1",
+			DNS:        []string{"10.8.0.26", "10.8.0.27"},
+			MACAddress: "b8:98:b0:03:4e:02",
+			DHCP:       false,
+		},
+	},
+	{
+		fixture: "fw2.03.html",
+		settings: NetworkSettings{
+			IPAddress:  "192.168.1.254",
+			Netmask:    "255.255.255.0",
+			Gateway:    "192.168.1.1",
+			DNS:        []string{"192.168.1.1"},
+			MACAddress: "b8:98:b0:05:11:a0",
+			DHCP:       true,
+		},
+	},
+}
+
+func readJunoFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "juno451", name))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %s", err)
+	}
+
+	return string(b)
+}
+
+func TestParseNetworkSettings(t *testing.T) {
+	for _, tt := range networkSettingsTests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			settings, err := parseNetworkSettings(readJunoFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(settings, tt.settings) {
+				t.Errorf("got %+v, expected %+v", settings, tt.settings)
+			}
+		})
+	}
+}
+
+func TestParseNetworkSettingsMissingIP(t *testing.T) {
+	if _, err := parseNetworkSettings("<html><body>Login required</body></html>"); err == nil {
+		t.Fatalf("expected an error for a page without an ip address")
+	}
+}
+
+func TestGetHardwareInfo4x1(t *testing.T) {
+	page := readJunoFixture(t, "fw1.21.html")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/aj.html" && r.URL.Query().Get("a") == string(infoPage):
+			w.Write([]byte(`{"info_val1":["AT-JUNO-451-HDBT","1.21.03"],"info_val2":[],"login_ur":1}`))
+		case r.URL.Path == "/":
+			w.Write([]byte(page))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vs := &AtlonaVideoSwitcher4x1{Address: strings.TrimPrefix(server.URL, "http://")}
+
+	hwinfo, err := vs.GetHardwareInfo(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if hwinfo.ModelName != "AT-JUNO-451-HDBT" || hwinfo.FirmwareVersion != "1.21.03" {
+		t.Errorf("got model %q firmware %v", hwinfo.ModelName, hwinfo.FirmwareVersion)
+	}
+
+	if !reflect.DeepEqual(hwinfo.NetworkInfo, networkSettingsTests[1].settings.NetworkInfo()) {
+		t.Errorf("got network info %+v, expected %+v", hwinfo.NetworkInfo, networkSettingsTests[1].settings.NetworkInfo())
+	}
+}
+
+var inputVideoInfoTests = []struct {
+	name string
+	json string
+	info InputVideoInfo
+}{
+	{
+		name: "string",
+		json: `"1920x1080p60 RGB HDCP1.4"`,
+		info: InputVideoInfo{Signal: true, Resolution: "1920x1080", RefreshRate: 60, ColorSpace: "RGB", HDCPVersion: "1.4"},
+	},
+	{
+		name: "interlaced string",
+		json: `"1920x1080i59.94 YCbCr 4:2:2"`,
+		info: InputVideoInfo{Signal: true, Resolution: "1920x1080", Interlaced: true, RefreshRate: 59.94, ColorSpace: "YCbCr 4:2:2"},
+	},
+	{
+		name: "refresh rate after @",
+		json: `"1280x720 @ 50Hz"`,
+		info: InputVideoInfo{Signal: true, Resolution: "1280x720", RefreshRate: 50},
+	},
+	{
+		name: "no refresh rate",
+		json: `"3840x2160 4:2:0 HDCP2.2"`,
+		info: InputVideoInfo{Signal: true, Resolution: "3840x2160", HDCPVersion: "2.2"},
+	},
+	{
+		name: "list",
+		json: `["1920x1080","60Hz","RGB","HDCP 1.4"]`,
+		info: InputVideoInfo{Signal: true, Resolution: "1920x1080", RefreshRate: 60, ColorSpace: "RGB", HDCPVersion: "1.4"},
+	},
+	{
+		name: "list with bare numbers",
+		json: `["1","1920x1080","60"]`,
+		info: InputVideoInfo{Signal: true, Resolution: "1920x1080"},
+	},
+	{
+		name: "list with version",
+		json: `["1920x1080","RGB","1.4"]`,
+		info: InputVideoInfo{Signal: true, Resolution: "1920x1080", ColorSpace: "RGB"},
+	},
+	{
+		name: "no signal",
+		json: `["0x0"]`,
+		info: InputVideoInfo{},
+	},
+	{
+		name: "empty",
+		json: `[]`,
+		info: InputVideoInfo{},
+	},
+	{
+		name: "object",
+		json: `{"res":"1920x1080"}`,
+		info: InputVideoInfo{Raw: json.RawMessage(`{"res":"1920x1080"}`)},
+	},
+	{
+		name: "number",
+		json: `1080`,
+		info: InputVideoInfo{Raw: json.RawMessage(`1080`)},
+	},
+}
+
+func TestInputVideoInfoUnmarshalJSON(t *testing.T) {
+	for _, tt := range inputVideoInfoTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info InputVideoInfo
+			if err := json.Unmarshal([]byte(tt.json), &info); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(info, tt.info) {
+				t.Errorf("got %+v, expected %+v", info, tt.info)
+			}
+		})
+	}
+}
+
+var systemInfoTests = []struct {
+	name string
+	json string
+	info SystemInfo
+	err  bool
+}{
+	{
+		name: "model and firmware",
+		json: `["AT-JUNO-451-HDBT"," 1.21.03 "]`,
+		info: SystemInfo{Model: "AT-JUNO-451-HDBT", Firmware: "1.21.03", Values: []string{"AT-JUNO-451-HDBT", "1.21.03"}},
+	},
+	{
+		name: "extra values",
+		json: `["AT-HDVS-210U-TX","1.3.4",2]`,
+		info: SystemInfo{Model: "AT-HDVS-210U-TX", Firmware: "1.3.4", Values: []string{"AT-HDVS-210U-TX", "1.3.4", "2"}},
+	},
+	{
+		name: "model only",
+		json: `["AT-JUNO-451-HDBT"]`,
+		info: SystemInfo{Model: "AT-JUNO-451-HDBT", Values: []string{"AT-JUNO-451-HDBT"}},
+	},
+	{
+		name: "object",
+		json: `{"model":"AT-JUNO-451-HDBT"}`,
+		err:  true,
+	},
+}
+
+func TestSystemInfoUnmarshalJSON(t *testing.T) {
+	for _, tt := range systemInfoTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info SystemInfo
+			err := json.Unmarshal([]byte(tt.json), &info)
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error, got %+v", info)
+			case !tt.err && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !tt.err && !reflect.DeepEqual(info, tt.info) {
+				t.Errorf("got %+v, expected %+v", info, tt.info)
+			}
+		})
+	}
+}
+
+func TestGetHardwareInfo4x1NoNetworkPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/aj.html" && r.URL.Query().Get("a") == string(infoPage) {
+			w.Write([]byte(`{"info_val1":["AT-JUNO-451-HDBT","1.21.03"],"info_val2":[],"login_ur":1}`))
+			return
+		}
+
+		// the main page doesn't have the network settings on it
+		w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vs := &AtlonaVideoSwitcher4x1{Address: strings.TrimPrefix(server.URL, "http://")}
+
+	hwinfo, err := vs.GetHardwareInfo(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if hwinfo.ModelName != "AT-JUNO-451-HDBT" || hwinfo.FirmwareVersion != "1.21.03" {
+		t.Errorf("got model %q firmware %v", hwinfo.ModelName, hwinfo.FirmwareVersion)
+	}
+
+	if hwinfo.NetworkInfo.IPAddress != "" {
+		t.Errorf("got network info %+v, expected none", hwinfo.NetworkInfo)
+	}
+}
+
+func TestGetHardwareInfo4x1UnexpectedVideoInfo(t *testing.T) {
+	videoInfos := []string{
+		`{"in1":"1920x1080p60"}`,
+		`[{"in1":"1920x1080p60"},"1920x1080p60 RGB"]`,
+		`"no inputs"`,
+	}
+
+	for _, videoInfo := range videoInfos {
+		t.Run(videoInfo, func(t *testing.T) {
+			page := readJunoFixture(t, "fw1.21.html")
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/aj.html" && r.URL.Query().Get("a") == string(infoPage) {
+					w.Write([]byte(`{"info_val1":["AT-JUNO-451-HDBT","1.21.03"],"info_val2":` + videoInfo + `,"login_ur":1}`))
+					return
+				}
+
+				w.Write([]byte(page))
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			vs := &AtlonaVideoSwitcher4x1{Address: strings.TrimPrefix(server.URL, "http://")}
+
+			hwinfo, err := vs.GetHardwareInfo(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if hwinfo.ModelName != "AT-JUNO-451-HDBT" || hwinfo.FirmwareVersion != "1.21.03" {
+				t.Errorf("got model %q firmware %v", hwinfo.ModelName, hwinfo.FirmwareVersion)
+			}
+
+			if _, err := vs.GetInfo(ctx); err != nil {
+				t.Errorf("unexpected error from GetInfo: %s", err)
+			}
+		})
+	}
+}
+
+func TestSetAudioDelay4x1OutOfRange(t *testing.T) {
+	vs := &AtlonaVideoSwitcher4x1{Address: "juno.invalid"}
+
+	for _, delay := range []int{-1, junoMaxAudioDelay + 1} {
+		if err := vs.SetAudioDelay(context.Background(), "", delay); err == nil || !strings.Contains(err.Error(), "between 0 and") {
+			t.Errorf("got error %v for a delay of %v, expected it to be out of range", err, delay)
+		}
+	}
+}
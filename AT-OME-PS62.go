@@ -2,7 +2,6 @@ package atlona
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -19,92 +18,91 @@ type AtlonaVideoSwitcher6x2 struct {
 	Address  string
 }
 
-type atlonaVideo struct {
-	Video struct {
-		VidOut struct {
-			HdmiOut struct {
-				HdmiOutA struct {
-					VideoSrc int `json:"videoSrc"`
-				} `json:"hdmiOutA"`
-				HdmiOutB struct {
-					VideoSrc int `json:"videoSrc"`
-				} `json:"hdmiOutB"`
-				Mirror struct {
-					VideoSrc int `json:"videoSrc"`
-				}
-			} `json:"hdmiOut"`
-		} `json:"vidOut"`
-	} `json:"video"`
-}
-
-type atlonaAudio struct {
-	Audio struct {
-		AudOut struct {
-			ZoneOut1 struct {
-				AnalogOut struct {
-					AudioMute  bool `json:"audioMute"`
-					AudioDelay int  `json:"audioDelay"`
-				} `json:"analogOut"`
-				AudioVol int `json:"audioVol"`
-			} `json:"zoneOut1"`
-			ZoneOut2 struct {
-				AnalogOut struct {
-					AudioMute  bool `json:"audioMute"`
-					AudioDelay int  `json:"audioDelay"`
-				} `json:"analogOut"`
-				AudioVol int `json:"audioVol"`
-			} `json:"zoneOut2"`
-		} `json:"audOut"`
-	} `json:"audio"`
-}
-
-type atlonaNetwork struct {
-	Network struct {
-		Eth0 struct {
-			MacAddr    string `json:"macAddr"`
-			DomainName string `json:"domainName"`
-			DNSServer1 string `json:"dnsServer1"`
-			DNSServer2 string `json:"dnsServer2"`
-			IPSettings struct {
-				TelnetPort int    `json:"telnetPort"`
-				Ipaddr     string `json:"ipaddr"`
-				Netmask    string `json:"netmask"`
-				Gateway    string `json:"gateway"`
-			} `json:"ipSettings"`
-			LastIpaddr string `json:"lastIpaddr"`
-			BootProto  string `json:"bootProto"`
-		} `json:"eth0"`
-	} `json:"network"`
-}
-
-//Atlona6x2HardwareInfo .
-type atlonaHardwareInfo struct {
-	System struct {
-		PowerStatus     string `json:"powerStatus"`
-		VendorID        string `json:"vendorID"`
-		Model           string `json:"model"`
-		SerialNumber    string `json:"serialNumber"`
-		FirmwareVersion struct {
-			Package          string `json:"package"`
-			MasterMCU        string `json:"masterMCU"`
-			TransceiverChipB string `json:"transceiverChip_B"`
-			TransceiverChipC string `json:"transceiverChip_C"`
-			TransceiverChipE string `json:"transceiverChip_E"`
-			TransceiverChipF string `json:"transceiverChip_F"`
-			Audio            string `json:"audio"`
-			Fpga             string `json:"fpga"`
-			Usb              string `json:"usb"`
-			ScalerChip       string `json:"scalerChip"`
-			ValensA          string `json:"valens_A"`
-			ValensB          string `json:"valens_B"`
-			ValensC          string `json:"valens_C"`
-			SlaveMCU         string `json:"slaveMCU"`
-			TransceiverChipA string `json:"transceiverChip_A"`
-		} `json:"firmwareVersion"`
-	} `json:"system"`
+// OmegaInfo is the system info for an Omega series switcher
+type OmegaInfo struct {
+	Model        string        `json:"model"`
+	SerialNumber string        `json:"serial_number"`
+	VendorID     string        `json:"vendor_id"`
+	PowerStatus  string        `json:"power_status"`
+	Firmware     OmegaFirmware `json:"firmware"`
+}
+
+// OmegaFirmware is the firmware version of each chip in an Omega series switcher
+type OmegaFirmware struct {
+	Package          string `json:"package"`
+	MasterMCU        string `json:"master_mcu"`
+	SlaveMCU         string `json:"slave_mcu"`
+	FPGA             string `json:"fpga"`
+	ScalerChip       string `json:"scaler_chip"`
+	Audio            string `json:"audio"`
+	USB              string `json:"usb"`
+	ValensA          string `json:"valens_a"`
+	ValensB          string `json:"valens_b"`
+	ValensC          string `json:"valens_c"`
+	TransceiverChipA string `json:"transceiver_chip_a"`
+	TransceiverChipB string `json:"transceiver_chip_b"`
+	TransceiverChipC string `json:"transceiver_chip_c"`
+	TransceiverChipE string `json:"transceiver_chip_e"`
+	TransceiverChipF string `json:"transceiver_chip_f"`
+}
+
+func newOmegaInfo(sys *OmegaSystem) OmegaInfo {
+	info := OmegaInfo{
+		Model:        derefString(sys.Model),
+		SerialNumber: derefString(sys.SerialNumber),
+		VendorID:     derefString(sys.VendorID),
+		PowerStatus:  derefString(sys.PowerStatus),
+	}
+
+	if fw := sys.FirmwareVersion; fw != nil {
+		info.Firmware = OmegaFirmware{
+			Package:          derefString(fw.Package),
+			MasterMCU:        derefString(fw.MasterMCU),
+			SlaveMCU:         derefString(fw.SlaveMCU),
+			FPGA:             derefString(fw.Fpga),
+			ScalerChip:       derefString(fw.ScalerChip),
+			Audio:            derefString(fw.Audio),
+			USB:              derefString(fw.Usb),
+			ValensA:          derefString(fw.ValensA),
+			ValensB:          derefString(fw.ValensB),
+			ValensC:          derefString(fw.ValensC),
+			TransceiverChipA: derefString(fw.TransceiverChipA),
+			TransceiverChipB: derefString(fw.TransceiverChipB),
+			TransceiverChipC: derefString(fw.TransceiverChipC),
+			TransceiverChipE: derefString(fw.TransceiverChipE),
+			TransceiverChipF: derefString(fw.TransceiverChipF),
+		}
+	}
+
+	return info
+}
+
+// OmegaNetworkSettings are the network settings of eth0 on an Omega series switcher
+type OmegaNetworkSettings struct {
+	DHCP       bool     `json:"dhcp"`
+	IPAddress  string   `json:"ip_address"`
+	Netmask    string   `json:"netmask"`
+	Gateway    string   `json:"gateway"`
+	DNS        []string `json:"dns,omitempty"`
+	DomainName string   `json:"domain_name,omitempty"`
+	TelnetPort int      `json:"telnet_port,omitempty"`
+
+	// MACAddress is read only; it is ignored by SetNetworkSettings
+	MACAddress string `json:"mac_address,omitempty"`
 }
 
+// boot protocols for eth0
+const (
+	omegaBootProtoDHCP   = "dhcp"
+	omegaBootProtoStatic = "static"
+)
+
 func (vs *AtlonaVideoSwitcher6x2) make6x2request(ctx context.Context, url, requestBody string) ([]byte, error) {
+	// the switcher always requires basic auth, so don't send a request that is sure to be rejected
+	if vs.Username == "" {
+		return nil, &AuthError{Address: vs.Address, Username: vs.Username}
+	}
+
 	payload := strings.NewReader(requestBody)
 
 	req, err := http.NewRequest("POST", url, payload)
@@ -113,121 +111,329 @@ func (vs *AtlonaVideoSwitcher6x2) make6x2request(ctx context.Context, url, reque
 	}
 	req = req.WithContext(ctx)
 	req.Header.Add("Content-Type", "application/json")
-	//This needs to be replaced with an environmental variable
-	req.Header.Add("Authorization", "Basic YWRtaW46QXRsb25h")
-	res, gerr := http.DefaultClient.Do(req)
-	if gerr != nil {
-		return nil, fmt.Errorf("error when making call: %w", gerr)
+	req.SetBasicAuth(vs.Username, vs.Password)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error when making call: %w", err)
 	}
 	defer res.Body.Close()
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error when making call: %w", gerr)
+		return nil, fmt.Errorf("error when reading response: %w", err)
 	}
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return nil, &AuthError{Address: vs.Address, Username: vs.Username}
+	}
+
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%v response received. body: %s", res.StatusCode, body)
+	}
+
 	return body, nil
 }
 
-//GetAudioVideoInputs .
+// video outputs on the switcher. the mirror output shows the same thing as one of the hdmi outputs
+const (
+	omegaOutputA      = "1"
+	omegaOutputB      = "2"
+	omegaOutputMirror = "mirror"
+)
+
+var omegaVideoOutputs = []string{omegaOutputA, omegaOutputB, omegaOutputMirror}
+
+// hdmiOutput returns the config for the named output in an hdmiOut section
+func hdmiOutput(hdmi *OmegaHdmiOut, output string) (**OmegaVideoOutput, error) {
+	switch output {
+	case omegaOutputA:
+		return &hdmi.HdmiOutA, nil
+	case omegaOutputB:
+		return &hdmi.HdmiOutB, nil
+	case omegaOutputMirror:
+		return &hdmi.Mirror, nil
+	default:
+		return nil, &InvalidOutputError{Output: output, Valid: omegaVideoOutputs}
+	}
+}
+
+// OmegaScalingMode is how the scaler picks the resolution of an output
+type OmegaScalingMode string
+
+// scaling modes supported by the switcher
+const (
+	// OmegaScalingAuto scales to the preferred resolution in the display's EDID
+	OmegaScalingAuto OmegaScalingMode = "auto"
+
+	// OmegaScalingManual scales to a fixed resolution
+	OmegaScalingManual OmegaScalingMode = "manual"
+
+	// OmegaScalingBypass passes the source through without scaling it
+	OmegaScalingBypass OmegaScalingMode = "bypass"
+)
+
+// OmegaOutputResolution is the scaler setting of an hdmi output. Resolution is only used
+// when ScalingMode is OmegaScalingManual; otherwise it is whatever the scaler picked.
+type OmegaOutputResolution struct {
+	Resolution  string           `json:"resolution"`
+	ScalingMode OmegaScalingMode `json:"scaling_mode"`
+}
+
+// omegaResolutions is every resolution the scaler can output
+var omegaResolutions = []string{
+	"640x480p60",
+	"800x600p60",
+	"1024x768p60",
+	"1280x720p50",
+	"1280x720p60",
+	"1280x768p60",
+	"1280x800p60",
+	"1280x1024p60",
+	"1360x768p60",
+	"1366x768p60",
+	"1440x900p60",
+	"1600x900p60",
+	"1600x1200p60",
+	"1680x1050p60",
+	"1920x1080p24",
+	"1920x1080p25",
+	"1920x1080p30",
+	"1920x1080p50",
+	"1920x1080p60",
+	"1920x1200p60",
+	"2560x1440p60",
+	"2560x1600p60",
+	"3840x2160p24",
+	"3840x2160p25",
+	"3840x2160p30",
+	"3840x2160p50",
+	"3840x2160p60",
+	"4096x2160p24",
+	"4096x2160p25",
+	"4096x2160p30",
+	"4096x2160p50",
+	"4096x2160p60",
+}
+
+// cec commands the switcher can send to a display
+const (
+	omegaCecPowerOn     = "powerOn"
+	omegaCecPowerOff    = "powerOff"
+	omegaCecInputSelect = "inputSelect"
+)
+
+// display power states reported over cec
+const (
+	omegaDisplayOn      = "on"
+	omegaDisplayStandby = "standby"
+)
+
+// extra audio blocks for the mic input. "mic" is the mic's gain and mute, and
+// "mic1"/"mic2" are the level of the mic in the mix on zone 1/2
+const (
+	omegaMicBlock     = "mic"
+	omegaMicMix1Block = "mic1"
+	omegaMicMix2Block = "mic2"
+)
+
+// mic gain and mix level ranges, in dB
+const (
+	omegaMicGainMin = 0
+	omegaMicGainMax = 60
+	omegaMicMixMin  = -90
+	omegaMicMixMax  = 10
+)
+
+// OmegaMicSettings is the state of the mic input
+type OmegaMicSettings struct {
+	// Gain is the mic preamp gain, in dB
+	Gain         int  `json:"gain"`
+	Muted        bool `json:"muted"`
+	PhantomPower bool `json:"phantom_power"`
+
+	// ZoneMix is the level of the mic in each zone's mix, in dB, keyed by zone ("1" or "2")
+	ZoneMix map[string]int `json:"zone_mix"`
+}
+
+// micMixZone returns the zone that a mic mix block is for
+func micMixZone(block string) (string, bool) {
+	switch block {
+	case omegaMicMix1Block:
+		return "1", true
+	case omegaMicMix2Block:
+		return "2", true
+	default:
+		return "", false
+	}
+}
+
+// audio sources for a zone, besides the embedded audio on an input (see zoneAudioSource)
+const (
+	// OmegaAudioFollowVideo plays the embedded audio of whatever is on the zone's video output
+	OmegaAudioFollowVideo = "follow"
+	OmegaAudioAnalog1     = "analog1"
+	OmegaAudioAnalog2     = "analog2"
+
+	// OmegaAudioMic plays only the mic mix
+	OmegaAudioMic = "mic"
+)
+
+// omegaMaxAudioDelay is the longest audio delay a zone accepts, in milliseconds
+const omegaMaxAudioDelay = 200
+
+// omegaInputCount is the number of video inputs on the switcher
+const omegaInputCount = 6
+
+// zoneAudioSource converts an audio source as named by the driver (an input number, or one of the OmegaAudio
+// constants) into the switcher's audioSrc
+func zoneAudioSource(source string) (string, error) {
+	switch source {
+	case OmegaAudioFollowVideo, OmegaAudioAnalog1, OmegaAudioAnalog2, OmegaAudioMic:
+		return source, nil
+	}
+
+	in, err := strconv.Atoi(source)
+	if err != nil || in < 1 || in > omegaInputCount {
+		return "", fmt.Errorf("invalid audio source %q", source)
+	}
+
+	return fmt.Sprintf("hdmi%v", in), nil
+}
+
+// zoneAudioSourceName is the inverse of zoneAudioSource
+func zoneAudioSourceName(audioSrc string) string {
+	if strings.HasPrefix(audioSrc, "hdmi") {
+		return strings.TrimPrefix(audioSrc, "hdmi")
+	}
+
+	if audioSrc == "" {
+		return OmegaAudioFollowVideo
+	}
+
+	return audioSrc
+}
+
+// HDBaseT ports on the switcher
+const (
+	omegaHdbtIn1 = "in1"
+	omegaHdbtIn2 = "in2"
+	omegaHdbtOut = "out"
+)
+
+// HDBaseTLink is the state of the link on an HDBaseT port
+type HDBaseTLink struct {
+	// Linked is whether the port has established an HDBaseT link
+	Linked bool `json:"linked"`
+
+	// RemoteConnected is whether the transmitter or receiver on the far end of the cable is connected
+	RemoteConnected bool `json:"remote_connected"`
+
+	// Quality is the link quality reported by the Valens chip, from 0 (no link) to 100
+	Quality int `json:"quality"`
+
+	// Errors is the number of errors seen on the link since it came up
+	Errors int `json:"errors"`
+
+	// CableLength is the estimated length of the cable, in meters
+	CableLength int `json:"cable_length"`
+}
+
+// zoneOutput returns the config for output ("1" or "2") in an audOut section
+func zoneOutput(aud *OmegaAudOut, output string) **OmegaZoneOut {
+	if output == "1" {
+		return &aud.ZoneOut1
+	}
+
+	return &aud.ZoneOut2
+}
+
+//GetAudioVideoInputs returns the input on each output ("1", "2", and "mirror" if mirroring is on). When a zone's
+//audio is split from the video on its output, the zone's audio source (see GetZoneAudioSource) is also returned
+//under "audio1" or "audio2". Those keys are left out while the zone's audio follows its output's video.
 func (vs *AtlonaVideoSwitcher6x2) GetAudioVideoInputs(ctx context.Context) (map[string]string, error) {
 	toReturn := make(map[string]string)
 
-	for i := 1; i < 3; i++ {
-		var resp atlonaVideo
-		url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-
-		requestBody := fmt.Sprintf(`
-		{
-			"getConfig": {
-				"video": {
-					"vidOut": {
-						"hdmiOut": {
-						}
-					}
-				}
+	cfg, err := vs.getConfig(ctx,
+		&OmegaConfig{Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: &OmegaHdmiOut{}}}},
+		&OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{}}},
+	)
+	if err != nil {
+		return toReturn, fmt.Errorf("An error occured while making the call: %w", err)
+	}
+
+	if cfg.Video == nil || cfg.Video.VidOut == nil || cfg.Video.VidOut.HdmiOut == nil {
+		return toReturn, fmt.Errorf("response did not include video outputs")
+	}
+
+	for _, output := range omegaVideoOutputs {
+		out, _ := hdmiOutput(cfg.Video.VidOut.HdmiOut, output)
+		if *out == nil || (*out).VideoSrc == nil {
+			if output == omegaOutputMirror {
+				// the mirror output isn't reported when mirroring is turned off
+				continue
 			}
-		}`)
 
-		body, gerr := vs.make6x2request(ctx, url, requestBody)
-		if gerr != nil {
-			return toReturn, fmt.Errorf("An error occured while making the call: %w", gerr)
+			return toReturn, fmt.Errorf("response did not include output %s", output)
 		}
 
-		err := json.Unmarshal([]byte(body), &resp)
-		if err != nil {
-			fmt.Printf("%s/n", body)
-			return toReturn, fmt.Errorf("error when unmarshalling the response: %w", err)
-		}
+		toReturn[output] = strconv.Itoa(*(*out).VideoSrc)
+	}
 
-		//Get the inputsrc for the requested output
-		input := ""
-		if i == 1 {
-			input = strconv.Itoa(resp.Video.VidOut.HdmiOut.HdmiOutA.VideoSrc)
-		} else if i == 2 {
-			input = strconv.Itoa(resp.Video.VidOut.HdmiOut.HdmiOutB.VideoSrc)
-		} else {
-			input = strconv.Itoa(resp.Video.VidOut.HdmiOut.Mirror.VideoSrc)
-		}
+	if cfg.Audio != nil && cfg.Audio.AudOut != nil {
+		// zone 1 plays with output 1, and zone 2 with output 2
+		for _, zone := range []string{"1", "2"} {
+			out := *zoneOutput(cfg.Audio.AudOut, zone)
+			if out == nil || out.AudioSrc == nil {
+				continue
+			}
 
-		toReturn[strconv.Itoa(i)] = input
+			source := zoneAudioSourceName(*out.AudioSrc)
+			if source != OmegaAudioFollowVideo && source != toReturn[zone] {
+				toReturn["audio"+zone] = source
+			}
+		}
 	}
 
 	return toReturn, nil
 }
 
-//SetAudioVideoInput .
+//SetAudioVideoInput changes the input on output ("1", "2", or "mirror")
 func (vs *AtlonaVideoSwitcher6x2) SetAudioVideoInput(ctx context.Context, output, input string) error {
 	in, err := strconv.Atoi(input)
 	if err != nil {
 		return fmt.Errorf("error when making call: %w", err)
 	}
-	url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-	requestBody := ""
-	if output == "1" {
-		requestBody = fmt.Sprintf(`
-		{
-			"setConfig":{
-				"video":{
-					"vidOut":{
-						"hdmiOut":{
-							"hdmiOutA":{
-								"videoSrc":%v
-							}
-						}
-					}
-				}
-			}
-		}`, in)
-	} else if output == "2" {
-		requestBody = fmt.Sprintf(`
-		{
-			"setConfig":{
-				"video":{
-					"vidOut":{
-						"hdmiOut":{
-							"hdmiOutB":{
-								"videoSrc":%v
-							}
-						}
-					}
-				}
-			}
-		}`, in)
-	} else {
-		requestBody = fmt.Sprintf(`
-		{"setConfig":{"video":{"vidOut":{"hdmiOut":{"mirror":{"videoSrc":%v}}}}}}
-		`, in)
+
+	hdmi := &OmegaHdmiOut{}
+	out, err := hdmiOutput(hdmi, output)
+	if err != nil {
+		return fmt.Errorf("unable to switch input: %w", err)
 	}
 
-	_, gerr := vs.make6x2request(ctx, url, requestBody)
-	if gerr != nil {
-		return fmt.Errorf("An error occured while making the call: %w", gerr)
+	*out = &OmegaVideoOutput{VideoSrc: intPtr(in)}
+
+	err = vs.setConfig(ctx, &OmegaConfig{
+		Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: hdmi}},
+	})
+	if err != nil {
+		return fmt.Errorf("An error occured while making the call: %w", err)
 	}
+
 	return nil
 }
 
 //SetVolume .
 func (vs *AtlonaVideoSwitcher6x2) SetVolume(ctx context.Context, output string, level int) error {
+	if output == omegaMicBlock {
+		gain := int(math.Round(float64(level*omegaMicGainMax) / 100))
+		return vs.SetMicGain(ctx, gain)
+	}
+
+	if zone, ok := micMixZone(output); ok {
+		return vs.SetMicMix(ctx, zone, level+omegaMicMixMin)
+	}
+
 	//Atlona volume levels are from -90 to 10 and the number we recieve is 0-100
 	//if volume level is supposed to be zero set it to zero (which is -90) on atlona
 
@@ -237,66 +443,86 @@ func (vs *AtlonaVideoSwitcher6x2) SetVolume(ctx context.Context, output string,
 		convertedVolume := -40 + math.Round(float64(level/2))
 		level = int(convertedVolume)
 	}
-	url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-	if output == "1" || output == "2" {
-		requestBody := fmt.Sprintf(`
-		{
-			"setConfig": {
-				"audio": {
-					"audOut": {
-						"zoneOut%s": {
-							"audioVol": %d
-						}
-					}
-				}
-			}
-		}`, output, level)
-		_, gerr := vs.make6x2request(ctx, url, requestBody)
-		if gerr != nil {
-			return fmt.Errorf("An error occured while making the call: %w", gerr)
-		}
-	} else {
+
+	if output != "1" && output != "2" {
 		return fmt.Errorf("Invalid Output. Valid Audio Output names are Audio1 and Audio2: you gave us %s", output)
 	}
+
+	aud := &OmegaAudOut{}
+	*zoneOutput(aud, output) = &OmegaZoneOut{AudioVol: intPtr(level)}
+
+	err := vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: aud}})
+	if err != nil {
+		return fmt.Errorf("An error occured while making the call: %w", err)
+	}
+
 	return nil
 }
 
+func (vs *AtlonaVideoSwitcher6x2) getAudOut(ctx context.Context) (*OmegaAudOut, error) {
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{
+		Audio: &OmegaAudio{AudOut: &OmegaAudOut{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Audio == nil || cfg.Audio.AudOut == nil {
+		return nil, fmt.Errorf("response did not include audio outputs")
+	}
+
+	return cfg.Audio.AudOut, nil
+}
+
 //GetVolumes .
 func (vs *AtlonaVideoSwitcher6x2) GetVolumes(ctx context.Context, blocks []string) (map[string]int, error) {
 	toReturn := make(map[string]int)
 
 	for _, block := range blocks {
-		var resp atlonaAudio
-		url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-		requestBody := fmt.Sprintf(`
-		{
-			"getConfig": {
-				"audio": {
-					"audOut": {
-						}
-					}
+		mixZone, isMix := micMixZone(block)
+		if block == omegaMicBlock || isMix {
+			mic, err := vs.GetMicSettings(ctx)
+			if err != nil {
+				return toReturn, err
+			}
+
+			if isMix {
+				level, ok := mic.ZoneMix[mixZone]
+				if !ok {
+					return toReturn, fmt.Errorf("response did not include the mic mix level for zone %s", mixZone)
 				}
-		}`)
-		body, gerr := vs.make6x2request(ctx, url, requestBody)
-		if gerr != nil {
-			return toReturn, fmt.Errorf("An error occured while making the call: %w", gerr)
+
+				toReturn[block] = level - omegaMicMixMin
+			} else {
+				toReturn[block] = int(math.Round(float64(mic.Gain*100) / omegaMicGainMax))
+			}
+
+			continue
+		}
+
+		if block != "1" && block != "2" {
+			return toReturn, fmt.Errorf("invalid Output. Valid Output names are 1, 2, mic, mic1, and mic2 you gave us %s", block)
 		}
 
-		err := json.Unmarshal([]byte(body), &resp) // here!
+		aud, err := vs.getAudOut(ctx)
 		if err != nil {
-			return toReturn, fmt.Errorf("error when unmarshalling the response: %w", err)
+			return toReturn, fmt.Errorf("An error occured while making the call: %w", err)
 		}
+
+		zone := *zoneOutput(aud, block)
+		if zone == nil {
+			return toReturn, fmt.Errorf("response did not include zone %s", block)
+		}
+
+		vol := derefInt(zone.AudioVol)
 		if block == "1" {
-			if resp.Audio.AudOut.ZoneOut1.AudioVol < -40 {
+			if vol < -40 {
 				toReturn[block] = 0
 			} else {
-				volume := ((resp.Audio.AudOut.ZoneOut1.AudioVol + 40) * 2)
-				toReturn[block] = volume
+				toReturn[block] = (vol + 40) * 2
 			}
-		} else if block == "2" {
-			toReturn[block] = resp.Audio.AudOut.ZoneOut2.AudioVol + 90
 		} else {
-			return toReturn, fmt.Errorf("invalid Output. Valid Output names are 1 and 2 you gave us %s", block)
+			toReturn[block] = vol + 90
 		}
 	}
 
@@ -308,40 +534,38 @@ func (vs *AtlonaVideoSwitcher6x2) GetMutes(ctx context.Context, blocks []string)
 	toReturn := make(map[string]bool)
 
 	for _, block := range blocks {
-		var resp atlonaAudio
-		if block == "1" || block == "2" {
-			url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-			requestBody := fmt.Sprintf(`
-			{
-				"getConfig": {
-					"audio":{
-						"audOut":{
-							"zoneOut%s":{
-								"analogOut": {				
-								}
-							}
-						}
-					}	
-				}	
-			}`, block)
-			body, gerr := vs.make6x2request(ctx, url, requestBody)
-			if gerr != nil {
-				return toReturn, fmt.Errorf("An error occured while making the call: %w", gerr)
-			}
-			err := json.Unmarshal([]byte(body), &resp)
+		if block == omegaMicBlock {
+			mic, err := vs.GetMicSettings(ctx)
 			if err != nil {
-				return toReturn, fmt.Errorf("error when unmarshalling the response: %w", err)
+				return toReturn, err
 			}
-		} else {
-			return toReturn, fmt.Errorf("Invalid Output. Valid Output names are 1 and 2 you gave us %s", block)
+
+			toReturn[block] = mic.Muted
+			continue
 		}
-		if block == "1" {
-			toReturn[block] = resp.Audio.AudOut.ZoneOut1.AnalogOut.AudioMute
-		} else if block == "2" {
-			toReturn[block] = resp.Audio.AudOut.ZoneOut2.AnalogOut.AudioMute
-		} else {
-			return toReturn, fmt.Errorf("Invalid Output. Valid Output names are 1 and 2 you gave us %s", block)
+
+		if block != "1" && block != "2" {
+			return toReturn, fmt.Errorf("Invalid Output. Valid Output names are 1, 2, and mic you gave us %s", block)
+		}
+
+		query := &OmegaAudOut{}
+		*zoneOutput(query, block) = &OmegaZoneOut{AnalogOut: &OmegaAnalogOut{}}
+
+		cfg, err := vs.getConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: query}})
+		if err != nil {
+			return toReturn, fmt.Errorf("An error occured while making the call: %w", err)
+		}
+
+		if cfg.Audio == nil || cfg.Audio.AudOut == nil {
+			return toReturn, fmt.Errorf("response did not include audio outputs")
 		}
+
+		zone := *zoneOutput(cfg.Audio.AudOut, block)
+		if zone == nil || zone.AnalogOut == nil {
+			return toReturn, fmt.Errorf("response did not include zone %s", block)
+		}
+
+		toReturn[block] = derefBool(zone.AnalogOut.AudioMute)
 	}
 
 	return toReturn, nil
@@ -349,88 +573,575 @@ func (vs *AtlonaVideoSwitcher6x2) GetMutes(ctx context.Context, blocks []string)
 
 //SetMute .
 func (vs *AtlonaVideoSwitcher6x2) SetMute(ctx context.Context, output string, muted bool) error {
-	url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-	if output == "1" || output == "2" {
-		requestBody := fmt.Sprintf(`
-		{
-			"setConfig": {
-				"audio": {
-					"audOut": {
-						"zoneOut%s": {
-							"analogOut": {
-								"audioMute": %v
-							}
-						}
-					}
-				}
-			}
-		}`, output, muted)
-		_, gerr := vs.make6x2request(ctx, url, requestBody)
-		if gerr != nil {
-			return fmt.Errorf("An error occured while making the call: %w", gerr)
+	if output == omegaMicBlock {
+		return vs.SetMicMute(ctx, muted)
+	}
+
+	if output != "1" && output != "2" {
+		return fmt.Errorf("Invalid Output. Valid Output names are Audio1, Audio2, and mic you gave us %s", output)
+	}
+
+	aud := &OmegaAudOut{}
+	*zoneOutput(aud, output) = &OmegaZoneOut{AnalogOut: &OmegaAnalogOut{AudioMute: boolPtr(muted)}}
+
+	err := vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: aud}})
+	if err != nil {
+		return fmt.Errorf("An error occured while making the call: %w", err)
+	}
+
+	return nil
+}
+
+//GetAudioDelays returns the audio delay on each zone, in milliseconds
+func (vs *AtlonaVideoSwitcher6x2) GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error) {
+	toReturn := make(map[string]int)
+
+	aud, err := vs.getAudOut(ctx)
+	if err != nil {
+		return toReturn, fmt.Errorf("unable to get audio delay: %w", err)
+	}
+
+	for _, block := range blocks {
+		if block != "1" && block != "2" {
+			return toReturn, &InvalidOutputError{Output: block, Valid: []string{"1", "2"}}
 		}
-	} else {
-		return fmt.Errorf("Invalid Output. Valid Output names are Audio1 and Audio2 you gave us %s", output)
+
+		zone := *zoneOutput(aud, block)
+		if zone == nil || zone.AnalogOut == nil || zone.AnalogOut.AudioDelay == nil {
+			return toReturn, fmt.Errorf("response did not include the audio delay for zone %s", block)
+		}
+
+		toReturn[block] = *zone.AnalogOut.AudioDelay
 	}
+
+	return toReturn, nil
+}
+
+//SetAudioDelay sets the audio delay on a zone, in milliseconds
+func (vs *AtlonaVideoSwitcher6x2) SetAudioDelay(ctx context.Context, output string, delay int) error {
+	if output != "1" && output != "2" {
+		return &InvalidOutputError{Output: output, Valid: []string{"1", "2"}}
+	}
+
+	if delay < 0 || delay > omegaMaxAudioDelay {
+		return fmt.Errorf("unable to set audio delay: delay must be between 0 and %v", omegaMaxAudioDelay)
+	}
+
+	aud := &OmegaAudOut{}
+	*zoneOutput(aud, output) = &OmegaZoneOut{AnalogOut: &OmegaAnalogOut{AudioDelay: intPtr(delay)}}
+
+	err := vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: aud}})
+	if err != nil {
+		return fmt.Errorf("unable to set audio delay: %w", err)
+	}
+
 	return nil
 }
 
 //GetHardwareInfo .
 func (vs *AtlonaVideoSwitcher6x2) GetHardwareInfo(ctx context.Context) (structs.HardwareInfo, error) {
-	var network atlonaNetwork
-	var hardware atlonaHardwareInfo
 	var resp structs.HardwareInfo
-	url := fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
-
-	//Get network info
-	requestBody := fmt.Sprintf(`
-	{
-		"getConfig": {
-			"network": {
-				"eth0":{
-				}
+
+	cfg, err := vs.getConfig(ctx,
+		&OmegaConfig{Network: &OmegaNetwork{Eth0: &OmegaEth{}}},
+		&OmegaConfig{System: &OmegaSystem{}},
+	)
+	if err != nil {
+		return resp, fmt.Errorf("An error occured while making the call: %w", err)
+	}
+
+	if cfg.Network == nil || cfg.Network.Eth0 == nil || cfg.System == nil {
+		return resp, fmt.Errorf("response did not include network and system info")
+	}
+
+	eth := cfg.Network.Eth0
+	if eth.IPSettings == nil {
+		eth.IPSettings = &OmegaIPSettings{}
+	}
+
+	//Load up the hardware struct
+	resp.Hostname = derefString(cfg.System.Model)
+	resp.ModelName = derefString(cfg.System.Model)
+	resp.NetworkInfo.MACAddress = derefString(eth.MacAddr)
+	resp.NetworkInfo.IPAddress = derefString(eth.IPSettings.Ipaddr)
+	resp.NetworkInfo.Gateway = derefString(eth.IPSettings.Gateway)
+	resp.PowerStatus = derefString(cfg.System.PowerStatus)
+	resp.SerialNumber = derefString(cfg.System.SerialNumber)
+	if cfg.System.FirmwareVersion != nil {
+		resp.FirmwareVersion = derefString(cfg.System.FirmwareVersion.Package)
+	}
+
+	return resp, nil
+}
+
+// GetOmegaInfo returns the model, serial number, vendor ID, and every firmware version on the switcher
+func (vs *AtlonaVideoSwitcher6x2) GetOmegaInfo(ctx context.Context) (OmegaInfo, error) {
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{System: &OmegaSystem{}})
+	if err != nil {
+		return OmegaInfo{}, fmt.Errorf("unable to get info: %w", err)
+	}
+
+	if cfg.System == nil {
+		return OmegaInfo{}, fmt.Errorf("unable to get info: response did not include system info")
+	}
+
+	return newOmegaInfo(cfg.System), nil
+}
+
+// GetInfo returns an OmegaInfo
+func (vs *AtlonaVideoSwitcher6x2) GetInfo(ctx context.Context) (interface{}, error) {
+	info, err := vs.GetOmegaInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// GetNetworkSettings gets the network settings of eth0
+func (vs *AtlonaVideoSwitcher6x2) GetNetworkSettings(ctx context.Context) (OmegaNetworkSettings, error) {
+	var settings OmegaNetworkSettings
+
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{Network: &OmegaNetwork{Eth0: &OmegaEth{}}})
+	if err != nil {
+		return settings, fmt.Errorf("unable to get network settings: %w", err)
+	}
+
+	if cfg.Network == nil || cfg.Network.Eth0 == nil {
+		return settings, fmt.Errorf("unable to get network settings: response did not include eth0")
+	}
+
+	eth := cfg.Network.Eth0
+	if eth.IPSettings == nil {
+		eth.IPSettings = &OmegaIPSettings{}
+	}
+
+	settings.DHCP = strings.EqualFold(derefString(eth.BootProto), omegaBootProtoDHCP)
+	settings.IPAddress = derefString(eth.IPSettings.Ipaddr)
+	settings.Netmask = derefString(eth.IPSettings.Netmask)
+	settings.Gateway = derefString(eth.IPSettings.Gateway)
+	settings.DomainName = derefString(eth.DomainName)
+	settings.TelnetPort = derefInt(eth.IPSettings.TelnetPort)
+	settings.MACAddress = derefString(eth.MacAddr)
+
+	for _, dns := range []*string{eth.DNSServer1, eth.DNSServer2} {
+		if d := derefString(dns); d != "" && d != "0.0.0.0" {
+			settings.DNS = append(settings.DNS, d)
+		}
+	}
+
+	// with dhcp on, the address the switcher is actually using is in lastIpaddr
+	if settings.DHCP && eth.LastIpaddr != nil {
+		settings.IPAddress = *eth.LastIpaddr
+	}
+
+	return settings, nil
+}
+
+// SetNetworkSettings changes the network settings of eth0. The static addresses are ignored if
+// DHCP is true, and TelnetPort is left alone if it is 0. A bad address, netmask, or gateway locks
+// everyone out of the switcher, so changing any of them (or turning DHCP on or off) is refused
+// unless changeAddress is true. After an address change, the switcher is only reachable once
+// Address is set to its new static address, or to the one its DHCP server hands out.
+func (vs *AtlonaVideoSwitcher6x2) SetNetworkSettings(ctx context.Context, settings OmegaNetworkSettings, changeAddress bool) error {
+	if settings.TelnetPort < 0 || settings.TelnetPort > 65535 {
+		return fmt.Errorf("unable to set network settings: invalid telnet port %v", settings.TelnetPort)
+	}
+
+	if len(settings.DNS) > 2 {
+		return fmt.Errorf("unable to set network settings: at most 2 dns servers can be set, got %v", len(settings.DNS))
+	}
+
+	if settings.DHCP {
+		for _, dns := range settings.DNS {
+			if err := validateIPv4("dns server", dns); err != nil {
+				return fmt.Errorf("unable to set network settings: %w", err)
 			}
 		}
-	}`)
-	body, gerr := vs.make6x2request(ctx, url, requestBody)
-	if gerr != nil {
-		return structs.HardwareInfo{}, fmt.Errorf("An error occured while making the call: %w", gerr)
+	} else if err := validateStaticIPv4(settings.IPAddress, settings.Netmask, settings.Gateway, settings.DNS...); err != nil {
+		return fmt.Errorf("unable to set network settings: %w", err)
+	}
+
+	cur, err := vs.GetNetworkSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to set network settings: %w", err)
+	}
+
+	if !changeAddress {
+		switch {
+		case settings.DHCP != cur.DHCP:
+			return fmt.Errorf("unable to set network settings: changing dhcp from %v to %v changes the switcher's address and was not confirmed", cur.DHCP, settings.DHCP)
+		case !settings.DHCP && settings.IPAddress != cur.IPAddress:
+			return fmt.Errorf("unable to set network settings: changing the ip address from %s to %s was not confirmed", cur.IPAddress, settings.IPAddress)
+		case !settings.DHCP && settings.Netmask != cur.Netmask:
+			return fmt.Errorf("unable to set network settings: changing the netmask from %s to %s was not confirmed", cur.Netmask, settings.Netmask)
+		case !settings.DHCP && settings.Gateway != cur.Gateway:
+			return fmt.Errorf("unable to set network settings: changing the gateway from %s to %s was not confirmed", cur.Gateway, settings.Gateway)
+		}
+	}
+
+	eth := &OmegaEth{
+		BootProto: stringPtr(omegaBootProtoStatic),
+	}
+
+	if settings.DHCP {
+		eth.BootProto = stringPtr(omegaBootProtoDHCP)
+	} else {
+		eth.IPSettings = &OmegaIPSettings{
+			Ipaddr:  stringPtr(settings.IPAddress),
+			Netmask: stringPtr(settings.Netmask),
+			Gateway: stringPtr(settings.Gateway),
+		}
 	}
 
-	err := json.Unmarshal([]byte(body), &network)
+	if settings.TelnetPort != 0 {
+		if eth.IPSettings == nil {
+			eth.IPSettings = &OmegaIPSettings{}
+		}
+
+		eth.IPSettings.TelnetPort = intPtr(settings.TelnetPort)
+	}
 
+	if len(settings.DNS) > 0 {
+		eth.DNSServer1 = stringPtr(settings.DNS[0])
+	}
+
+	if len(settings.DNS) > 1 {
+		eth.DNSServer2 = stringPtr(settings.DNS[1])
+	}
+
+	if settings.DomainName != "" {
+		eth.DomainName = stringPtr(settings.DomainName)
+	}
+
+	// send everything at once so the switcher doesn't end up half configured
+	if err := vs.setConfig(ctx, &OmegaConfig{Network: &OmegaNetwork{Eth0: eth}}); err != nil {
+		return fmt.Errorf("unable to set network settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetHDBaseTLinks returns the link status of each HDBaseT port on the switcher, keyed by
+// port ("in1", "in2", or "out"). Ports that the switcher doesn't report are left out.
+func (vs *AtlonaVideoSwitcher6x2) GetHDBaseTLinks(ctx context.Context) (map[string]HDBaseTLink, error) {
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{Video: &OmegaVideo{Hdbt: &OmegaHdbt{}}})
 	if err != nil {
-		return resp, fmt.Errorf("error when unmarshalling the response: %w", err)
+		return nil, fmt.Errorf("unable to get HDBaseT links: %w", err)
+	}
+
+	if cfg.Video == nil || cfg.Video.Hdbt == nil {
+		return nil, fmt.Errorf("unable to get HDBaseT links: response did not include HDBaseT ports")
+	}
+
+	ports := map[string]*OmegaHdbtPort{
+		omegaHdbtIn1: cfg.Video.Hdbt.HdbtIn1,
+		omegaHdbtIn2: cfg.Video.Hdbt.HdbtIn2,
+		omegaHdbtOut: cfg.Video.Hdbt.HdbtOut,
 	}
 
-	//Get other hardware info
-	requestBody = fmt.Sprintf(`
-	{
-		"getConfig": {
-			"system": {}
+	links := make(map[string]HDBaseTLink)
+	for name, port := range ports {
+		if port == nil {
+			continue
 		}
-	}`)
-	body, gerr = vs.make6x2request(ctx, url, requestBody)
-	if gerr != nil {
-		return structs.HardwareInfo{}, fmt.Errorf("An error occured while making the call: %w", gerr)
+
+		links[name] = HDBaseTLink{
+			Linked:          derefBool(port.LinkStatus),
+			RemoteConnected: derefBool(port.RemoteConnected),
+			Quality:         derefInt(port.LinkQuality),
+			Errors:          derefInt(port.ErrorCount),
+			CableLength:     derefInt(port.CableLength),
+		}
+	}
+
+	return links, nil
+}
+
+// SupportedResolutions returns the resolutions that can be passed to SetOutputResolution
+func (vs *AtlonaVideoSwitcher6x2) SupportedResolutions() []string {
+	return append([]string(nil), omegaResolutions...)
+}
+
+// scalerOutput returns the config for output in hdmi, if output has its own scaler. the mirror output
+// always matches the output it mirrors, so it can't be set on its own
+func scalerOutput(hdmi *OmegaHdmiOut, output string) (**OmegaVideoOutput, error) {
+	if output == omegaOutputMirror {
+		return nil, &InvalidOutputError{Output: output, Valid: []string{omegaOutputA, omegaOutputB}}
+	}
+
+	return hdmiOutput(hdmi, output)
+}
+
+// GetOutputResolution gets the scaler setting of output ("1" or "2")
+func (vs *AtlonaVideoSwitcher6x2) GetOutputResolution(ctx context.Context, output string) (OmegaOutputResolution, error) {
+	var res OmegaOutputResolution
+
+	if _, err := scalerOutput(&OmegaHdmiOut{}, output); err != nil {
+		return res, fmt.Errorf("unable to get output resolution: %w", err)
 	}
-	err = json.Unmarshal([]byte(body), &hardware)
+
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{
+		Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: &OmegaHdmiOut{}}},
+	})
 	if err != nil {
-		return resp, fmt.Errorf("error when unmarshalling the response: %w", err)
+		return res, fmt.Errorf("unable to get output resolution: %w", err)
 	}
 
-	//Load up the hardware struct
-	resp.Hostname = hardware.System.Model
-	resp.ModelName = hardware.System.Model
-	resp.NetworkInfo.MACAddress = network.Network.Eth0.MacAddr
-	resp.NetworkInfo.IPAddress = network.Network.Eth0.IPSettings.Ipaddr
-	resp.NetworkInfo.Gateway = network.Network.Eth0.IPSettings.Gateway
-	resp.PowerStatus = hardware.System.PowerStatus
-	return resp, nil
+	if cfg.Video == nil || cfg.Video.VidOut == nil || cfg.Video.VidOut.HdmiOut == nil {
+		return res, fmt.Errorf("unable to get output resolution: response did not include video outputs")
+	}
+
+	out, _ := scalerOutput(cfg.Video.VidOut.HdmiOut, output)
+	if *out == nil || ((*out).Resolution == nil && (*out).ScalingMode == nil) {
+		return res, fmt.Errorf("unable to get output resolution: response did not include output %s", output)
+	}
+
+	res.Resolution = derefString((*out).Resolution)
+	res.ScalingMode = OmegaScalingMode(derefString((*out).ScalingMode))
+	return res, nil
 }
 
-//GetInfo .
-func (vs *AtlonaVideoSwitcher6x2) GetInfo(ctx context.Context) (interface{}, error) {
-	var info interface{}
-	return info, fmt.Errorf("not currently implemented")
+// SetOutputResolution changes the scaler setting of output ("1" or "2"). res.Resolution must be one
+// of SupportedResolutions if res.ScalingMode is OmegaScalingManual, and is ignored otherwise.
+func (vs *AtlonaVideoSwitcher6x2) SetOutputResolution(ctx context.Context, output string, res OmegaOutputResolution) error {
+	hdmi := &OmegaHdmiOut{}
+	out, err := scalerOutput(hdmi, output)
+	if err != nil {
+		return fmt.Errorf("unable to set output resolution: %w", err)
+	}
+
+	*out = &OmegaVideoOutput{ScalingMode: stringPtr(string(res.ScalingMode))}
+
+	switch res.ScalingMode {
+	case OmegaScalingAuto, OmegaScalingBypass:
+	case OmegaScalingManual:
+		if !isOmegaResolution(res.Resolution) {
+			return fmt.Errorf("unable to set output resolution: unsupported resolution %q", res.Resolution)
+		}
+
+		(*out).Resolution = stringPtr(res.Resolution)
+	default:
+		return fmt.Errorf("unable to set output resolution: invalid scaling mode %q", res.ScalingMode)
+	}
+
+	if err := vs.setConfig(ctx, &OmegaConfig{Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: hdmi}}}); err != nil {
+		return fmt.Errorf("unable to set output resolution: %w", err)
+	}
+
+	return nil
+}
+
+func isOmegaResolution(res string) bool {
+	for _, r := range omegaResolutions {
+		if r == res {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sendCec sends command to the display on output ("1", "2", or "mirror")
+func (vs *AtlonaVideoSwitcher6x2) sendCec(ctx context.Context, output, command string) error {
+	hdmi := &OmegaHdmiOut{}
+	out, err := hdmiOutput(hdmi, output)
+	if err != nil {
+		return err
+	}
+
+	*out = &OmegaVideoOutput{Cec: &OmegaCec{Command: stringPtr(command)}}
+	return vs.setConfig(ctx, &OmegaConfig{Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: hdmi}}})
+}
+
+// DisplayPowerOn turns on the display on output ("1", "2", or "mirror") over HDMI-CEC
+func (vs *AtlonaVideoSwitcher6x2) DisplayPowerOn(ctx context.Context, output string) error {
+	if err := vs.sendCec(ctx, output, omegaCecPowerOn); err != nil {
+		return fmt.Errorf("unable to power on display: %w", err)
+	}
+
+	return nil
+}
+
+// DisplayPowerOff puts the display on output ("1", "2", or "mirror") into standby over HDMI-CEC
+func (vs *AtlonaVideoSwitcher6x2) DisplayPowerOff(ctx context.Context, output string) error {
+	if err := vs.sendCec(ctx, output, omegaCecPowerOff); err != nil {
+		return fmt.Errorf("unable to power off display: %w", err)
+	}
+
+	return nil
+}
+
+// DisplaySelectInput switches the display on output ("1", "2", or "mirror") to the input the switcher is plugged into
+func (vs *AtlonaVideoSwitcher6x2) DisplaySelectInput(ctx context.Context, output string) error {
+	if err := vs.sendCec(ctx, output, omegaCecInputSelect); err != nil {
+		return fmt.Errorf("unable to select display input: %w", err)
+	}
+
+	return nil
+}
+
+// GetDisplayPower returns whether the display on output ("1", "2", or "mirror") is on. It returns an
+// UnsupportedError if the display doesn't report its power state over HDMI-CEC.
+func (vs *AtlonaVideoSwitcher6x2) GetDisplayPower(ctx context.Context, output string) (bool, error) {
+	if _, err := hdmiOutput(&OmegaHdmiOut{}, output); err != nil {
+		return false, fmt.Errorf("unable to get display power: %w", err)
+	}
+
+	cfg, err := vs.getConfig(ctx, &OmegaConfig{
+		Video: &OmegaVideo{VidOut: &OmegaVidOut{HdmiOut: &OmegaHdmiOut{}}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to get display power: %w", err)
+	}
+
+	if cfg.Video == nil || cfg.Video.VidOut == nil || cfg.Video.VidOut.HdmiOut == nil {
+		return false, fmt.Errorf("unable to get display power: response did not include video outputs")
+	}
+
+	out, _ := hdmiOutput(cfg.Video.VidOut.HdmiOut, output)
+	if *out == nil || (*out).Cec == nil {
+		return false, &UnsupportedError{Feature: "display power state on output " + output}
+	}
+
+	switch strings.ToLower(derefString((*out).Cec.DisplayPower)) {
+	case omegaDisplayOn:
+		return true, nil
+	case omegaDisplayStandby:
+		return false, nil
+	default:
+		// the display didn't answer the power status request
+		return false, &UnsupportedError{Feature: "display power state on output " + output}
+	}
+}
+
+// GetMicSettings gets the gain, mute, phantom power, and zone mix levels of the mic input
+func (vs *AtlonaVideoSwitcher6x2) GetMicSettings(ctx context.Context) (OmegaMicSettings, error) {
+	var settings OmegaMicSettings
+
+	cfg, err := vs.getConfig(ctx,
+		&OmegaConfig{Audio: &OmegaAudio{AudIn: &OmegaAudIn{Mic: &OmegaMic{}}}},
+		&OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{}}},
+	)
+	if err != nil {
+		return settings, fmt.Errorf("unable to get mic settings: %w", err)
+	}
+
+	if cfg.Audio == nil || cfg.Audio.AudIn == nil || cfg.Audio.AudIn.Mic == nil {
+		return settings, fmt.Errorf("unable to get mic settings: response did not include the mic input")
+	}
+
+	mic := cfg.Audio.AudIn.Mic
+	settings.Gain = derefInt(mic.Gain)
+	settings.Muted = derefBool(mic.AudioMute)
+	settings.PhantomPower = derefBool(mic.PhantomPower)
+	settings.ZoneMix = make(map[string]int)
+
+	if cfg.Audio.AudOut != nil {
+		for _, zone := range []string{"1", "2"} {
+			if out := *zoneOutput(cfg.Audio.AudOut, zone); out != nil && out.MicMixVol != nil {
+				settings.ZoneMix[zone] = *out.MicMixVol
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+func (vs *AtlonaVideoSwitcher6x2) setMic(ctx context.Context, mic *OmegaMic) error {
+	return vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudIn: &OmegaAudIn{Mic: mic}}})
+}
+
+// SetMicGain sets the mic preamp gain, in dB
+func (vs *AtlonaVideoSwitcher6x2) SetMicGain(ctx context.Context, gain int) error {
+	if gain < omegaMicGainMin || gain > omegaMicGainMax {
+		return fmt.Errorf("unable to set mic gain: gain must be between %v and %v dB", omegaMicGainMin, omegaMicGainMax)
+	}
+
+	if err := vs.setMic(ctx, &OmegaMic{Gain: intPtr(gain)}); err != nil {
+		return fmt.Errorf("unable to set mic gain: %w", err)
+	}
+
+	return nil
+}
+
+// SetMicMute mutes or unmutes the mic input
+func (vs *AtlonaVideoSwitcher6x2) SetMicMute(ctx context.Context, muted bool) error {
+	if err := vs.setMic(ctx, &OmegaMic{AudioMute: boolPtr(muted)}); err != nil {
+		return fmt.Errorf("unable to set mic mute: %w", err)
+	}
+
+	return nil
+}
+
+// SetMicPhantomPower turns 48V phantom power on the mic input on or off
+func (vs *AtlonaVideoSwitcher6x2) SetMicPhantomPower(ctx context.Context, on bool) error {
+	if err := vs.setMic(ctx, &OmegaMic{PhantomPower: boolPtr(on)}); err != nil {
+		return fmt.Errorf("unable to set mic phantom power: %w", err)
+	}
+
+	return nil
+}
+
+// SetMicMix sets the level of the mic in zone's ("1" or "2") mix, in dB
+func (vs *AtlonaVideoSwitcher6x2) SetMicMix(ctx context.Context, zone string, level int) error {
+	if zone != "1" && zone != "2" {
+		return &InvalidOutputError{Output: zone, Valid: []string{"1", "2"}}
+	}
+
+	if level < omegaMicMixMin || level > omegaMicMixMax {
+		return fmt.Errorf("unable to set mic mix: level must be between %v and %v dB", omegaMicMixMin, omegaMicMixMax)
+	}
+
+	aud := &OmegaAudOut{}
+	*zoneOutput(aud, zone) = &OmegaZoneOut{MicMixVol: intPtr(level)}
+
+	if err := vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: aud}}); err != nil {
+		return fmt.Errorf("unable to set mic mix: %w", err)
+	}
+
+	return nil
+}
+
+// GetZoneAudioSource returns the audio source on zone ("1" or "2"): an input number for the embedded
+// audio on that input, or one of the OmegaAudio constants
+func (vs *AtlonaVideoSwitcher6x2) GetZoneAudioSource(ctx context.Context, zone string) (string, error) {
+	if zone != "1" && zone != "2" {
+		return "", &InvalidOutputError{Output: zone, Valid: []string{"1", "2"}}
+	}
+
+	aud, err := vs.getAudOut(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to get audio source: %w", err)
+	}
+
+	out := *zoneOutput(aud, zone)
+	if out == nil {
+		return "", fmt.Errorf("unable to get audio source: response did not include zone %s", zone)
+	}
+
+	return zoneAudioSourceName(derefString(out.AudioSrc)), nil
+}
+
+// SetZoneAudioSource changes the audio source on zone ("1" or "2"), breaking it away from the video on its
+// output. source is an input number for the embedded audio on that input, or one of the OmegaAudio constants;
+// OmegaAudioFollowVideo puts the zone back to following its output's video.
+func (vs *AtlonaVideoSwitcher6x2) SetZoneAudioSource(ctx context.Context, zone, source string) error {
+	if zone != "1" && zone != "2" {
+		return &InvalidOutputError{Output: zone, Valid: []string{"1", "2"}}
+	}
+
+	audioSrc, err := zoneAudioSource(source)
+	if err != nil {
+		return fmt.Errorf("unable to set audio source: %w", err)
+	}
+
+	aud := &OmegaAudOut{}
+	*zoneOutput(aud, zone) = &OmegaZoneOut{AudioSrc: stringPtr(audioSrc)}
+
+	if err := vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: aud}}); err != nil {
+		return fmt.Errorf("unable to set audio source: %w", err)
+	}
+
+	return nil
 }
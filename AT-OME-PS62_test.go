@@ -0,0 +1,361 @@
+package atlona
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const omegaEth0Reply = `{"network":{"eth0":{"macAddr":"b8:98:b0:0a:0b:0c","bootProto":"static","dnsServer1":"10.8.0.26",` +
+	`"ipSettings":{"ipaddr":"10.5.34.60","netmask":"255.255.255.0","gateway":"10.5.34.1","telnetPort":23}}}}`
+
+var omegaTests = []struct {
+	name     string
+	replies  []string
+	call     func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error)
+	requests []string
+	result   interface{}
+	err      bool
+}{
+	// routing
+	{
+		name: "get inputs without mirror",
+		replies: []string{`{"video":{"vidOut":{"hdmiOut":{"hdmiOutA":{"videoSrc":1},"hdmiOutB":{"videoSrc":4}}}},` +
+			`"audio":{"audOut":{"zoneOut1":{"audioSrc":"follow"},"zoneOut2":{"audioSrc":"hdmi4"}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetAudioVideoInputs(ctx)
+		},
+		requests: []string{`{"getConfig":{"video":{"vidOut":{"hdmiOut":{}}},"audio":{"audOut":{}}}}`},
+		result:   map[string]string{"1": "1", "2": "4"},
+	},
+	{
+		name: "get inputs with split audio",
+		replies: []string{`{"video":{"vidOut":{"hdmiOut":{"hdmiOutA":{"videoSrc":1},"hdmiOutB":{"videoSrc":4},"mirror":{"videoSrc":1}}}},` +
+			`"audio":{"audOut":{"zoneOut1":{"audioSrc":"hdmi3"},"zoneOut2":{"audioSrc":"analog2"}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetAudioVideoInputs(ctx)
+		},
+		requests: []string{`{"getConfig":{"video":{"vidOut":{"hdmiOut":{}}},"audio":{"audOut":{}}}}`},
+		result:   map[string]string{"1": "1", "2": "4", "mirror": "1", "audio1": "3", "audio2": OmegaAudioAnalog2},
+	},
+	{
+		name: "route mirror",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetAudioVideoInput(ctx, "mirror", "3")
+		},
+		requests: []string{`{"setConfig":{"video":{"vidOut":{"hdmiOut":{"mirror":{"videoSrc":3}}}}}}`},
+	},
+	{
+		name: "route invalid output",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetAudioVideoInput(ctx, "3", "1")
+		},
+		err: true,
+	},
+
+	// audio delay
+	{
+		name:    "get audio delays",
+		replies: []string{`{"audio":{"audOut":{"zoneOut1":{"analogOut":{"audioDelay":0}},"zoneOut2":{"analogOut":{"audioDelay":120}}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetAudioDelays(ctx, []string{"1", "2"})
+		},
+		requests: []string{`{"getConfig":{"audio":{"audOut":{}}}}`},
+		result:   map[string]int{"1": 0, "2": 120},
+	},
+	{
+		name: "set audio delay",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetAudioDelay(ctx, "2", 120)
+		},
+		requests: []string{`{"setConfig":{"audio":{"audOut":{"zoneOut2":{"analogOut":{"audioDelay":120}}}}}}`},
+	},
+	{
+		name: "audio delay too long",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetAudioDelay(ctx, "1", omegaMaxAudioDelay+1)
+		},
+		err: true,
+	},
+
+	// firmware inventory
+	{
+		name:    "get info",
+		replies: []string{`{"system":{"model":"AT-OME-PS62","serialNumber":"1234","firmwareVersion":{"package":"1.0.15","valens_A":"2.1"}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetOmegaInfo(ctx)
+		},
+		requests: []string{`{"getConfig":{"system":{}}}`},
+		result:   OmegaInfo{Model: "AT-OME-PS62", SerialNumber: "1234", Firmware: OmegaFirmware{Package: "1.0.15", ValensA: "2.1"}},
+	},
+
+	// network settings
+	{
+		name:    "get network settings",
+		replies: []string{omegaEth0Reply},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetNetworkSettings(ctx)
+		},
+		requests: []string{`{"getConfig":{"network":{"eth0":{}}}}`},
+		result: OmegaNetworkSettings{
+			IPAddress:  "10.5.34.60",
+			Netmask:    "255.255.255.0",
+			Gateway:    "10.5.34.1",
+			DNS:        []string{"10.8.0.26"},
+			TelnetPort: 23,
+			MACAddress: "b8:98:b0:0a:0b:0c",
+		},
+	},
+	{
+		name:    "set dns on the same address",
+		replies: []string{omegaEth0Reply},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetNetworkSettings(ctx, OmegaNetworkSettings{
+				IPAddress: "10.5.34.60",
+				Netmask:   "255.255.255.0",
+				Gateway:   "10.5.34.1",
+				DNS:       []string{"10.8.0.27"},
+			}, false)
+		},
+		requests: []string{
+			`{"getConfig":{"network":{"eth0":{}}}}`,
+			`{"setConfig":{"network":{"eth0":{"bootProto":"static","dnsServer1":"10.8.0.27",` +
+				`"ipSettings":{"ipaddr":"10.5.34.60","netmask":"255.255.255.0","gateway":"10.5.34.1"}}}}}`,
+		},
+	},
+	{
+		name:    "unconfirmed gateway change",
+		replies: []string{omegaEth0Reply},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetNetworkSettings(ctx, OmegaNetworkSettings{
+				IPAddress: "10.5.34.60",
+				Netmask:   "255.255.255.0",
+				Gateway:   "10.5.34.254",
+			}, false)
+		},
+		requests: []string{`{"getConfig":{"network":{"eth0":{}}}}`},
+		err:      true,
+	},
+	{
+		name:    "unconfirmed dhcp change",
+		replies: []string{omegaEth0Reply},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetNetworkSettings(ctx, OmegaNetworkSettings{DHCP: true}, false)
+		},
+		requests: []string{`{"getConfig":{"network":{"eth0":{}}}}`},
+		err:      true,
+	},
+	{
+		name:    "confirmed dhcp change",
+		replies: []string{omegaEth0Reply},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetNetworkSettings(ctx, OmegaNetworkSettings{DHCP: true}, true)
+		},
+		requests: []string{
+			`{"getConfig":{"network":{"eth0":{}}}}`,
+			`{"setConfig":{"network":{"eth0":{"bootProto":"dhcp"}}}}`,
+		},
+	},
+	{
+		name: "invalid static settings",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetNetworkSettings(ctx, OmegaNetworkSettings{
+				IPAddress: "10.5.34.60",
+				Netmask:   "255.255.255.0",
+				Gateway:   "10.6.0.1",
+			}, true)
+		},
+		err: true,
+	},
+
+	// HDBaseT
+	{
+		name: "get HDBaseT links",
+		replies: []string{`{"video":{"hdbt":{"hdbtIn1":{"linkStatus":true,"remoteConnected":true,"linkQuality":92,"errorCount":3,"cableLength":40},` +
+			`"hdbtOut":{"linkStatus":false}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetHDBaseTLinks(ctx)
+		},
+		requests: []string{`{"getConfig":{"video":{"hdbt":{}}}}`},
+		result: map[string]HDBaseTLink{
+			"in1": {Linked: true, RemoteConnected: true, Quality: 92, Errors: 3, CableLength: 40},
+			"out": {},
+		},
+	},
+
+	// scaler
+	{
+		name:    "get output resolution",
+		replies: []string{`{"video":{"vidOut":{"hdmiOut":{"hdmiOutB":{"videoSrc":2,"resolution":"1920x1080p60","scalingMode":"auto"}}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetOutputResolution(ctx, "2")
+		},
+		requests: []string{`{"getConfig":{"video":{"vidOut":{"hdmiOut":{}}}}}`},
+		result:   OmegaOutputResolution{Resolution: "1920x1080p60", ScalingMode: OmegaScalingAuto},
+	},
+	{
+		name: "set manual resolution",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetOutputResolution(ctx, "1", OmegaOutputResolution{Resolution: "1280x720p60", ScalingMode: OmegaScalingManual})
+		},
+		requests: []string{`{"setConfig":{"video":{"vidOut":{"hdmiOut":{"hdmiOutA":{"resolution":"1280x720p60","scalingMode":"manual"}}}}}}`},
+	},
+	{
+		name: "set unsupported resolution",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetOutputResolution(ctx, "1", OmegaOutputResolution{Resolution: "1280x720p30", ScalingMode: OmegaScalingManual})
+		},
+		err: true,
+	},
+	{
+		name: "set mirror resolution",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetOutputResolution(ctx, "mirror", OmegaOutputResolution{ScalingMode: OmegaScalingAuto})
+		},
+		err: true,
+	},
+
+	// cec
+	{
+		name: "display power on",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.DisplayPowerOn(ctx, "2")
+		},
+		requests: []string{`{"setConfig":{"video":{"vidOut":{"hdmiOut":{"hdmiOutB":{"cec":{"command":"powerOn"}}}}}}}`},
+	},
+	{
+		name:    "get display power",
+		replies: []string{`{"video":{"vidOut":{"hdmiOut":{"hdmiOutA":{"videoSrc":1,"cec":{"displayPower":"standby"}}}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetDisplayPower(ctx, "1")
+		},
+		requests: []string{`{"getConfig":{"video":{"vidOut":{"hdmiOut":{}}}}}`},
+		result:   false,
+	},
+	{
+		name:    "display doesn't report power",
+		replies: []string{`{"video":{"vidOut":{"hdmiOut":{"hdmiOutA":{"videoSrc":1,"cec":{"displayPower":"unknown"}}}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetDisplayPower(ctx, "1")
+		},
+		requests: []string{`{"getConfig":{"video":{"vidOut":{"hdmiOut":{}}}}}`},
+		err:      true,
+	},
+
+	// mic
+	{
+		name:    "get mic settings",
+		replies: []string{`{"audio":{"audIn":{"mic":{"gain":30,"audioMute":true,"phantomPower":false}},"audOut":{"zoneOut1":{"micMixVol":-20},"zoneOut2":{"micMixVol":0}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetMicSettings(ctx)
+		},
+		requests: []string{`{"getConfig":{"audio":{"audIn":{"mic":{}},"audOut":{}}}}`},
+		result:   OmegaMicSettings{Gain: 30, Muted: true, ZoneMix: map[string]int{"1": -20, "2": 0}},
+	},
+	{
+		name:    "get mic volumes",
+		replies: []string{`{"audio":{"audIn":{"mic":{"gain":30}},"audOut":{"zoneOut1":{"micMixVol":-20}}}}`, `{"audio":{"audIn":{"mic":{"gain":30}},"audOut":{"zoneOut1":{"micMixVol":-20}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetVolumes(ctx, []string{"mic", "mic1"})
+		},
+		requests: []string{`{"getConfig":{"audio":{"audIn":{"mic":{}},"audOut":{}}}}`, `{"getConfig":{"audio":{"audIn":{"mic":{}},"audOut":{}}}}`},
+		result:   map[string]int{"mic": 50, "mic1": 70},
+	},
+	{
+		name:    "missing mic mix",
+		replies: []string{`{"audio":{"audIn":{"mic":{"gain":30}},"audOut":{"zoneOut1":{"micMixVol":-20}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetVolumes(ctx, []string{"mic2"})
+		},
+		requests: []string{`{"getConfig":{"audio":{"audIn":{"mic":{}},"audOut":{}}}}`},
+		err:      true,
+	},
+	{
+		name: "set mic gain",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetMicGain(ctx, 42)
+		},
+		requests: []string{`{"setConfig":{"audio":{"audIn":{"mic":{"gain":42}}}}}`},
+	},
+	{
+		name: "set mic mix",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetMicMix(ctx, "2", -15)
+		},
+		requests: []string{`{"setConfig":{"audio":{"audOut":{"zoneOut2":{"micMixVol":-15}}}}}`},
+	},
+	{
+		name: "set mic phantom power",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetMicPhantomPower(ctx, true)
+		},
+		requests: []string{`{"setConfig":{"audio":{"audIn":{"mic":{"phantomPower":true}}}}}`},
+	},
+	{
+		name: "mic gain out of range",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetMicGain(ctx, 61)
+		},
+		err: true,
+	},
+
+	// zone audio
+	{
+		name:    "get zone audio source",
+		replies: []string{`{"audio":{"audOut":{"zoneOut1":{"audioSrc":"hdmi3"},"zoneOut2":{"audioSrc":"analog1"}}}}`},
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return vs.GetZoneAudioSource(ctx, "1")
+		},
+		requests: []string{`{"getConfig":{"audio":{"audOut":{}}}}`},
+		result:   "3",
+	},
+	{
+		name: "set zone audio source",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetZoneAudioSource(ctx, "2", "5")
+		},
+		requests: []string{`{"setConfig":{"audio":{"audOut":{"zoneOut2":{"audioSrc":"hdmi5"}}}}}`},
+	},
+	{
+		name: "set zone audio source to follow video",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetZoneAudioSource(ctx, "1", OmegaAudioFollowVideo)
+		},
+		requests: []string{`{"setConfig":{"audio":{"audOut":{"zoneOut1":{"audioSrc":"follow"}}}}}`},
+	},
+	{
+		name: "invalid zone audio source",
+		call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (interface{}, error) {
+			return nil, vs.SetZoneAudioSource(ctx, "1", "7")
+		},
+		err: true,
+	},
+}
+
+func TestAtlonaVideoSwitcher6x2(t *testing.T) {
+	for _, tt := range omegaTests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &omegaDevice{replies: tt.replies}
+			vs, closeFunc := newOmegaSwitcher(d)
+			defer closeFunc()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := tt.call(ctx, vs)
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error, got %v", result)
+			case !tt.err && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			checkOmegaRequests(t, d, tt.requests)
+
+			if tt.result != nil && !reflect.DeepEqual(result, tt.result) {
+				t.Errorf("got %+v, expected %+v", result, tt.result)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,22 +21,28 @@ type AtlonaVideoSwitcher5x1 struct {
 	Address  string
 	once     sync.Once
 	pool     wspool.Pool
+	rpc      *rpcClient
 	Logger   wspool.Logger
+
+	// Timeout is how long to wait for the switcher to reply to a request. Defaults to 5 seconds.
+	// It is read on every request, so it can be changed after the switcher is first used
+	Timeout time.Duration
+}
+
+// avSettings is the "AV Settings" config section
+type avSettings struct {
+	Source          string `json:"source"`
+	Autoswitch      int    `json:"Autoswitch"`
+	Volume          string `json:"Volume"`
+	HDMIAudioMute   int    `json:"HDMI Audio Mute"`
+	HDBTAudioMute   int    `json:"HDBT Audio Mute"`
+	AnalogAudioMute int    `json:"Analog Audio Mute"`
+	AudioDelay      *int   `json:"Audio Delay"`
 }
 
+// room is the result of a config_get for the "AV Settings" section
 type room struct {
-	Jsonrpc string `json:"jsonrpc"`
-	ID      string `json:"id"`
-	Result  struct {
-		AVSettings struct {
-			Source          string `json:"source"`
-			Autoswitch      int    `json:"Autoswitch"`
-			Volume          string `json:"Volume"`
-			HDMIAudioMute   int    `json:"HDMI Audio Mute"`
-			HDBTAudioMute   int    `json:"HDBT Audio Mute"`
-			AnalogAudioMute int    `json:"Analog Audio Mute"`
-		} `json:"AV Settings"`
-	} `json:"result"`
+	AVSettings avSettings `json:"AV Settings"`
 }
 
 func (vs *AtlonaVideoSwitcher5x1) createPool() {
@@ -50,6 +57,16 @@ func (vs *AtlonaVideoSwitcher5x1) createPool() {
 		Logger:        vs.Logger,
 	}
 
+	vs.rpc = newRPCClient(&vs.pool, vs.Logger, func() time.Duration {
+		return vs.Timeout
+	})
+}
+
+// Notifications returns the notifications the switcher sends on its own. Notifications are only
+// read while the driver is waiting for a reply, and are dropped if nobody reads them.
+func (vs *AtlonaVideoSwitcher5x1) Notifications() <-chan Notification {
+	vs.once.Do(vs.createPool)
+	return vs.rpc.notifications
 }
 
 func createConnectionFunc(address string) wspool.NewConnectionFunc {
@@ -69,71 +86,18 @@ func createConnectionFunc(address string) wspool.NewConnectionFunc {
 //GetAudioVideoInputs .
 func (vs *AtlonaVideoSwitcher5x1) GetAudioVideoInputs(ctx context.Context) (map[string]string, error) {
 	toReturn := make(map[string]string)
-	vs.once.Do(vs.createPool)
-
-	var roomInfo room
-	var bytes []byte
-
-	err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-		body := `{
-			"jsonrpc": "2.0",
-			"id": "<configuration_id>",
-			"method": "config_get",
-			"params": {
-				"sections": [
-					"AV Settings"
-				]
-			}
-		}`
-
-		vs.pool.Logger.Infof("writing message to Get Input")
-
-		err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-		if err != nil {
-			return fmt.Errorf("failed to write message: %s", err.Error())
-		}
-
-		if vs.Logger != nil {
-			vs.Logger.Infof("reading message from websocket")
-		}
-
-		timeout := time.Now()
-		timeout = timeout.Add(time.Second * 5)
-
-		err = ws.SetReadDeadline(timeout)
-		if err != nil {
-			return fmt.Errorf("failed to set readDeadline: %s", err)
-		}
-
-		_, bytes, err = ws.ReadMessage()
-		if err != nil {
-			vs.Logger.Errorf("failed reading message from websocket: %s", err)
-			return fmt.Errorf("failed to read message: %s", err)
-		}
-
-		vs.pool.Logger.Infof("read message from Get Input")
-
-		return nil
-	})
 
+	roomInfo, err := vs.getRoom(ctx)
 	if err != nil {
-		return toReturn, fmt.Errorf("failed to read message from channel: %s", err.Error())
+		return toReturn, fmt.Errorf("unable to get input: %w", err)
 	}
 
-	err = json.Unmarshal(bytes, &roomInfo)
-
-	if err != nil {
-		return toReturn, fmt.Errorf("failed to unmarshal message: %s", err.Error())
-	}
-
-	toReturn[""] = roomInfo.Result.AVSettings.Source[6:]
+	toReturn[""] = strings.TrimPrefix(roomInfo.AVSettings.Source, "input ")
 	return toReturn, nil
 }
 
 //SetAudioVideoInput .
 func (vs *AtlonaVideoSwitcher5x1) SetAudioVideoInput(ctx context.Context, output, input string) error {
-	vs.once.Do(vs.createPool)
-
 	intInput, nerr := strconv.Atoi(input)
 
 	if nerr != nil {
@@ -144,40 +108,11 @@ func (vs *AtlonaVideoSwitcher5x1) SetAudioVideoInput(ctx context.Context, output
 		return fmt.Errorf("Invalid Input. The input requested must be between 1-5. The input you requested was %v", intInput)
 	}
 
-	err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-		body := fmt.Sprintf(`{
-			"jsonrpc": "2.0",
-			"id": "<configuration_id>",
-			"method": "config_set",
-			"params": {
-			  "AV Settings": {
-				"source": "input %s"
-			  }
-			}
-		  }`, input)
-
-		if vs.Logger != nil {
-			vs.Logger.Infof("writing message")
-		}
-
-		vs.pool.Logger.Infof("writing message to Set Input")
-
-		err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-		if err != nil {
-			return fmt.Errorf("failed to write message: %s", err.Error())
-		}
-
-		vs.pool.Logger.Infof("successful wrote message to set Input")
-
-		return nil
+	err := vs.setAVSettings(ctx, map[string]interface{}{
+		"source": "input " + input,
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to read message from channel: %s", err.Error())
-	}
-
-	if err != nil {
-		return fmt.Errorf("failed to read message from channel: %s", err.Error())
+		return fmt.Errorf("unable to set input: %w", err)
 	}
 
 	return nil
@@ -185,8 +120,6 @@ func (vs *AtlonaVideoSwitcher5x1) SetAudioVideoInput(ctx context.Context, output
 
 //SetVolume .
 func (vs *AtlonaVideoSwitcher5x1) SetVolume(ctx context.Context, output string, level int) error {
-	vs.once.Do(vs.createPool)
-
 	if level == 0 {
 		level = -80
 	} else {
@@ -194,35 +127,11 @@ func (vs *AtlonaVideoSwitcher5x1) SetVolume(ctx context.Context, output string,
 		level = int(convertedVolume)
 	}
 
-	err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-		body := fmt.Sprintf(`{
-			"jsonrpc": "2.0",
-			"id": "<configuration_id>",
-			"method": "config_set",
-			"params": {
-			  "AV Settings": {
-				"Volume": "%v"
-			  }
-			}
-		  }`, level)
-
-		vs.pool.Logger.Infof("writing message to Set Volume")
-
-		err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-		if err != nil {
-			return fmt.Errorf("failed to write message: %s", err.Error())
-		}
-
-		vs.pool.Logger.Infof("successfully wrote to Set Volume")
-
-		return nil
+	err := vs.setAVSettings(ctx, map[string]interface{}{
+		"Volume": strconv.Itoa(level),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to read message from channel: %s", err.Error())
-	}
-
-	if err != nil {
-		return fmt.Errorf("failed to read message from channel: %s", err.Error())
+		return fmt.Errorf("unable to set volume: %w", err)
 	}
 
 	return nil
@@ -232,59 +141,12 @@ func (vs *AtlonaVideoSwitcher5x1) SetVolume(ctx context.Context, output string,
 func (vs *AtlonaVideoSwitcher5x1) GetVolumes(ctx context.Context, blocks []string) (map[string]int, error) {
 	toReturn := make(map[string]int)
 
-	vs.once.Do(vs.createPool)
-
-	var roomInfo room
-	var bytes []byte
-
-	err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-		body := `{
-			"jsonrpc": "2.0",
-			"id": "<configuration_id>",
-			"method": "config_get",
-			"params": {
-				"sections": [
-					"AV Settings"
-				]
-			}
-		}`
-
-		vs.pool.Logger.Infof("writing message to Get Volume")
-
-		err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-		if err != nil {
-			return fmt.Errorf("failed to write message: %s", err.Error())
-		}
-
-		timeout := time.Now()
-		timeout = timeout.Add(time.Second * 5)
-
-		err = ws.SetReadDeadline(timeout)
-		if err != nil {
-			return fmt.Errorf("failed to set readDeadline: %s", err)
-		}
-
-		_, bytes, err = ws.ReadMessage()
-		if err != nil {
-			vs.Logger.Errorf("failed reading message from websocket: %s", err)
-			return fmt.Errorf("failed to read message: %s", err)
-		}
-
-		vs.pool.Logger.Infof("read message from Get volume")
-
-		return nil
-	})
+	roomInfo, err := vs.getRoom(ctx)
 	if err != nil {
-		return toReturn, fmt.Errorf("failed to read message from channel: %s", err.Error())
+		return toReturn, fmt.Errorf("unable to get volume: %w", err)
 	}
 
-	err = json.Unmarshal(bytes, &roomInfo)
-
-	if err != nil {
-		return toReturn, fmt.Errorf("failed to unmarshal response: %s", err.Error())
-	}
-
-	volumeLevel, err := strconv.Atoi(roomInfo.Result.AVSettings.Volume)
+	volumeLevel, err := strconv.Atoi(roomInfo.AVSettings.Volume)
 	if err != nil {
 		return toReturn, fmt.Errorf("failed to convert volume to int: %s", err.Error())
 	}
@@ -306,79 +168,20 @@ func (vs *AtlonaVideoSwitcher5x1) GetVolumes(ctx context.Context, blocks []strin
 func (vs *AtlonaVideoSwitcher5x1) GetMutes(ctx context.Context, blocks []string) (map[string]bool, error) {
 	toReturn := make(map[string]bool)
 
-	for _, block := range blocks {
-		vs.once.Do(vs.createPool)
-
-		var roomInfo room
-		var bytes []byte
-
-		err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-			body := `{
-				"jsonrpc": "2.0",
-				"id": "<configuration_id>",
-				"method": "config_get",
-				"params": {
-					"sections": [
-						"AV Settings"
-					]
-				}
-			}`
-
-			vs.pool.Logger.Infof("writing message to Get Muted")
-
-			err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-			if err != nil {
-				return fmt.Errorf("failed to write message: %s", err.Error())
-			}
-
-			timeout := time.Now()
-			timeout = timeout.Add(time.Second * 5)
-
-			err = ws.SetReadDeadline(timeout)
-			if err != nil {
-				return fmt.Errorf("failed to set readDeadline: %s", err)
-			}
-
-			_, bytes, err = ws.ReadMessage()
-			if err != nil {
-				vs.Logger.Errorf("failed reading message from websocket: %s", err)
-				return fmt.Errorf("failed to read message: %s", err)
-			}
-
-			vs.pool.Logger.Infof("read message from Get Muted")
-
-			return nil
-		})
-		if err != nil {
-			return toReturn, fmt.Errorf("failed to read message from channel: %s", err.Error())
-		}
-
-		err = json.Unmarshal(bytes, &roomInfo)
-
-		if err != nil {
-			return toReturn, fmt.Errorf("failed to unmarshal response: %s", err.Error())
-		}
+	roomInfo, err := vs.getRoom(ctx)
+	if err != nil {
+		return toReturn, fmt.Errorf("unable to get mutes: %w", err)
+	}
 
+	for _, block := range blocks {
 		switch block {
 		case "HDMI":
-			isMuted, err := strconv.ParseBool(fmt.Sprintf("%v", roomInfo.Result.AVSettings.HDMIAudioMute))
-			if err != nil {
-				return toReturn, fmt.Errorf("failed to parse bool: %s", err.Error())
-			}
-			toReturn[block] = isMuted
+			toReturn[block] = roomInfo.AVSettings.HDMIAudioMute != 0
 		case "HDBT":
-			isMuted, err := strconv.ParseBool(fmt.Sprintf("%v", roomInfo.Result.AVSettings.HDBTAudioMute))
-			if err != nil {
-				return toReturn, fmt.Errorf("failed to parse bool: %s", err.Error())
-			}
-			toReturn[block] = isMuted
+			toReturn[block] = roomInfo.AVSettings.HDBTAudioMute != 0
 		default:
 			// Analog
-			isMuted, err := strconv.ParseBool(fmt.Sprintf("%v", roomInfo.Result.AVSettings.AnalogAudioMute))
-			if err != nil {
-				return toReturn, fmt.Errorf("failed to parse bool: %s", err.Error())
-			}
-			toReturn[block] = isMuted
+			toReturn[block] = roomInfo.AVSettings.AnalogAudioMute != 0
 		}
 	}
 
@@ -387,9 +190,7 @@ func (vs *AtlonaVideoSwitcher5x1) GetMutes(ctx context.Context, blocks []string)
 
 //SetMute .
 func (vs *AtlonaVideoSwitcher5x1) SetMute(ctx context.Context, output string, muted bool) error {
-	vs.once.Do(vs.createPool)
-
-	var audioBlock string
+	var key string
 	muteInt := 0
 
 	if muted {
@@ -398,44 +199,196 @@ func (vs *AtlonaVideoSwitcher5x1) SetMute(ctx context.Context, output string, mu
 
 	switch output {
 	case "HDMI":
-		audioBlock = fmt.Sprintf(`"HDMI Audio Mute": %v`, muteInt)
+		key = "HDMI Audio Mute"
 	case "HDBT":
-		audioBlock = fmt.Sprintf(`"HDBT Audio Mute": %v`, muteInt)
+		key = "HDBT Audio Mute"
 	default:
 		// Analog
-		audioBlock = fmt.Sprintf(`"Analog Audio Mute": %v`, muteInt)
+		key = "Analog Audio Mute"
 	}
 
-	err := vs.pool.Do(ctx, func(ws *websocket.Conn) error {
-		body := fmt.Sprintf(`{
-			"jsonrpc": "2.0",
-			"id": "<configuration_id>",
-			"method": "config_set",
-			"params": {
-			  "AV Settings": {
-				%s
-			  }
-			}
-		  }`, audioBlock)
+	err := vs.setAVSettings(ctx, map[string]interface{}{
+		key: muteInt,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set mute: %w", err)
+	}
 
-		vs.pool.Logger.Infof("writing message to set Muted")
+	return nil
+}
 
-		err := ws.WriteMessage(websocket.TextMessage, []byte(body))
-		if err != nil {
-			return fmt.Errorf("failed to write message: %s", err.Error())
+func (vs *AtlonaVideoSwitcher5x1) getRoom(ctx context.Context) (room, error) {
+	vs.once.Do(vs.createPool)
+
+	var roomInfo room
+
+	params := map[string][]string{
+		"sections": {"AV Settings"},
+	}
+
+	if err := vs.rpc.call(ctx, "config_get", params, &roomInfo); err != nil {
+		return roomInfo, err
+	}
+
+	return roomInfo, nil
+}
+
+// setAVSettings writes settings to the "AV Settings" section, and waits for the switcher to accept them
+func (vs *AtlonaVideoSwitcher5x1) setAVSettings(ctx context.Context, settings map[string]interface{}) error {
+	vs.once.Do(vs.createPool)
+
+	params := map[string]interface{}{
+		"AV Settings": settings,
+	}
+
+	var result json.RawMessage
+	if err := vs.rpc.call(ctx, "config_set", params, &result); err != nil {
+		return err
+	}
+
+	return vs.checkSetResult(result)
+}
+
+// setResult is an object result from a config_set: either the settings that were applied, or a status or error
+type setResult struct {
+	AVSettings json.RawMessage `json:"AV Settings"`
+	Success    *bool           `json:"success"`
+	Status     string          `json:"status"`
+	Error      json.RawMessage `json:"error"`
+	Message    string          `json:"message"`
+}
+
+// checkSetResult returns an error if result (from a config_set) says that the switcher didn't apply the change.
+// errors are usually sent back as a JSON-RPC error, but some firmware puts them in the result instead.
+// results that don't look like any of the known shapes are an error, since the change may not have been applied
+func (vs *AtlonaVideoSwitcher5x1) checkSetResult(result json.RawMessage) error {
+	if raw := strings.TrimSpace(string(result)); raw == "" || raw == "null" {
+		return nil
+	}
+
+	var ok bool
+	if err := json.Unmarshal(result, &ok); err == nil {
+		if !ok {
+			return &DeviceError{Address: vs.Address, Message: "config_set was rejected"}
+		}
+
+		return nil
+	}
+
+	var msg string
+	if err := json.Unmarshal(result, &msg); err == nil {
+		switch strings.ToLower(strings.TrimSpace(msg)) {
+		case "", "ok", "success":
+			return nil
+		default:
+			return &DeviceError{Address: vs.Address, Message: msg}
 		}
+	}
 
-		vs.pool.Logger.Infof("wrote message to set Muted")
+	var obj setResult
+	if err := json.Unmarshal(result, &obj); err != nil {
+		return fmt.Errorf("unexpected config_set result: %s", result)
+	}
+
+	switch {
+	case len(obj.Error) > 0 && string(obj.Error) != "null":
+		// the error is either a message, or an object with one
+		var errMsg string
+		if err := json.Unmarshal(obj.Error, &errMsg); err != nil {
+			errMsg = string(obj.Error)
+		}
+
+		return &DeviceError{Address: vs.Address, Message: errMsg}
+	case obj.Success != nil && !*obj.Success:
+		if obj.Message == "" {
+			obj.Message = "config_set was rejected"
+		}
 
+		return &DeviceError{Address: vs.Address, Message: obj.Message}
+	case obj.Status != "":
+		switch strings.ToLower(strings.TrimSpace(obj.Status)) {
+		case "ok", "success":
+			return nil
+		}
+
+		if obj.Message == "" {
+			obj.Message = obj.Status
+		}
+
+		return &DeviceError{Address: vs.Address, Message: obj.Message}
+	case obj.Success != nil, len(obj.AVSettings) > 0:
+		// the settings that were applied
 		return nil
-	})
+	}
+
+	return fmt.Errorf("unexpected config_set result: %s", result)
+}
+
+// audio outputs on the switcher. they all share one audio delay, so "" is also accepted as an audio delay output
+var sw52AudioOutputs = []string{"HDMI", "HDBT", "Analog"}
+
+// sw52MaxAudioDelay is the longest audio delay the switcher accepts, in milliseconds
+const sw52MaxAudioDelay = 500
+
+// checkSW52AudioDelayOutput returns an *InvalidOutputError if output isn't one of the switcher's audio outputs
+func checkSW52AudioDelayOutput(output string) error {
+	if output == "" {
+		return nil
+	}
+
+	for _, valid := range sw52AudioOutputs {
+		if output == valid {
+			return nil
+		}
+	}
+
+	return &InvalidOutputError{Output: output, Valid: sw52AudioOutputs}
+}
+
+//GetAudioDelays returns the audio delay in milliseconds. The delay applies to every audio output
+func (vs *AtlonaVideoSwitcher5x1) GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error) {
+	toReturn := make(map[string]int)
 
+	for _, block := range blocks {
+		if err := checkSW52AudioDelayOutput(block); err != nil {
+			return toReturn, err
+		}
+	}
+
+	roomInfo, err := vs.getRoom(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read message from channel: %s", err.Error())
+		return toReturn, fmt.Errorf("unable to get audio delay: %w", err)
 	}
 
-	return nil
+	if roomInfo.AVSettings.AudioDelay == nil {
+		return toReturn, &UnsupportedError{Feature: "audio delay"}
+	}
 
+	for _, block := range blocks {
+		toReturn[block] = *roomInfo.AVSettings.AudioDelay
+	}
+
+	return toReturn, nil
+}
+
+//SetAudioDelay sets the audio delay in milliseconds. The delay applies to every audio output
+func (vs *AtlonaVideoSwitcher5x1) SetAudioDelay(ctx context.Context, output string, delay int) error {
+	if err := checkSW52AudioDelayOutput(output); err != nil {
+		return err
+	}
+
+	if delay < 0 || delay > sw52MaxAudioDelay {
+		return fmt.Errorf("unable to set audio delay: delay must be between 0 and %v", sw52MaxAudioDelay)
+	}
+
+	err := vs.setAVSettings(ctx, map[string]interface{}{
+		"Audio Delay": delay,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set audio delay: %w", err)
+	}
+
+	return nil
 }
 
 //GetHardwareInfo .
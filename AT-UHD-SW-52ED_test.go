@@ -0,0 +1,56 @@
+package atlona
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var checkSetResultTests = []struct {
+	result string
+	err    bool
+
+	// deviceErr is whether the error should be a *DeviceError
+	deviceErr bool
+}{
+	{result: ``},
+	{result: `null`},
+	{result: `true`},
+	{result: `false`, err: true, deviceErr: true},
+	{result: `"ok"`},
+	{result: `" Success "`},
+	{result: `""`},
+	{result: `"Invalid value for Audio Delay"`, err: true, deviceErr: true},
+	{result: `{"AV Settings":{"Audio Delay":120}}`},
+	{result: `{"success":true}`},
+	{result: `{"success":false,"message":"busy"}`, err: true, deviceErr: true},
+	{result: `{"status":"ok"}`},
+	{result: `{"status":"error","message":"Audio Delay out of range"}`, err: true, deviceErr: true},
+	{result: `{"error":"Audio Delay out of range"}`, err: true, deviceErr: true},
+	{result: `{"error":{"code":3,"message":"read only"}}`, err: true, deviceErr: true},
+	{result: `{"error":null,"AV Settings":{}}`},
+	{result: `{"unknown":1}`, err: true},
+	{result: `42`, err: true},
+	{result: `["AV Settings"]`, err: true},
+}
+
+func TestCheckSetResult(t *testing.T) {
+	vs := &AtlonaVideoSwitcher5x1{Address: "sw52.example"}
+
+	for _, tt := range checkSetResultTests {
+		t.Run(tt.result, func(t *testing.T) {
+			err := vs.checkSetResult(json.RawMessage(tt.result))
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error")
+			case !tt.err && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var devErr *DeviceError
+			if errors.As(err, &devErr) != tt.deviceErr {
+				t.Errorf("got error %v, expected *DeviceError to be %v", err, tt.deviceErr)
+			}
+		})
+	}
+}
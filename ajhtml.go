@@ -0,0 +1,215 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ajPage is a page that can be requested with aj.html?a=<page>
+type ajPage string
+
+// pages shared by the aj.html based switchers
+const (
+	avSettingsPage ajPage = "avs"
+	infoPage       ajPage = "info"
+	networkPage    ajPage = "net"
+)
+
+// ajCommand is a command that can be sent with aj.html?a=command&cmd=<command>
+type ajCommand string
+
+// switchAVCommand routes audio and video from in to out. inputs and outputs are 1-based
+func switchAVCommand(in, out int) (ajCommand, error) {
+	if in < 1 || out < 1 {
+		return "", fmt.Errorf("invalid route x%vAVx%v: inputs and outputs start at 1", in, out)
+	}
+
+	return ajCommand(fmt.Sprintf("x%vAVx%v", in, out)), nil
+}
+
+// audioDelayCommand sets the audio delay, in milliseconds
+func audioDelayCommand(delay int) ajCommand {
+	return ajCommand(fmt.Sprintf("ADelay%v", delay))
+}
+
+// ajClient talks to the aj.html page/command protocol that Atlona's web based
+// switchers (HDVS-210U, JUNO-451, ...) share. If Username is set, it logs in
+// whenever the switcher reports that the session has expired.
+type ajClient struct {
+	Address  string
+	Username string
+	Password string
+
+	once      sync.Once
+	client    *http.Client
+	sessionMu sync.Mutex
+}
+
+// ajLogin is the part of every aj.html response that says whether we are logged in
+type ajLogin struct {
+	LoginUr   *int   `json:"login_ur"`
+	LoginUser string `json:"login_user"`
+}
+
+func (c *ajClient) createClient() {
+	// the switchers keep track of the session with a cookie
+	jar, _ := cookiejar.New(nil)
+	c.client = &http.Client{Jar: jar}
+}
+
+// ajLoginFormRegex matches the login form on the page the switcher sends instead of the one asked for when the session has expired
+var ajLoginFormRegex = regexp.MustCompile(`(?i)<input[^>]+name\s*=\s*["']?login_(user|pass)\b`)
+
+// loggedIn reports whether the switcher accepted the request, based on the response's status and login fields
+func loggedIn(status int, body []byte) bool {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return false
+	}
+
+	var login ajLogin
+	if err := json.Unmarshal(body, &login); err != nil {
+		// html pages and command responses aren't json, but the login page has a login form
+		return !ajLoginFormRegex.Match(body)
+	}
+
+	return login.LoginUr == nil || *login.LoginUr != 0
+}
+
+func (c *ajClient) get(ctx context.Context, path string) (int, []byte, error) {
+	c.once.Do(c.createClient)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s%s", c.Address, path), nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("unable to read response: %w", err)
+	}
+
+	return resp.StatusCode, b, nil
+}
+
+// do requests path, logging in and trying again if the session has expired
+func (c *ajClient) do(ctx context.Context, path string) ([]byte, error) {
+	status, b, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// web auth is turned off, or we already have a session
+	if loggedIn(status, b) {
+		if status/100 != 2 {
+			return nil, fmt.Errorf("%v response received. body: %s", status, b)
+		}
+
+		return b, nil
+	}
+
+	// there aren't any credentials to log in with
+	if c.Username == "" {
+		return nil, &AuthError{Address: c.Address, Username: c.Username}
+	}
+
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+
+	status, b, err = c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loggedIn(status, b) {
+		return nil, &AuthError{Address: c.Address, Username: c.Username}
+	}
+
+	if status/100 != 2 {
+		return nil, fmt.Errorf("%v response received. body: %s", status, b)
+	}
+
+	return b, nil
+}
+
+func (c *ajClient) login(ctx context.Context) error {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	form := url.Values{}
+	form.Set("a", "login")
+	form.Set("login_user", c.Username)
+	form.Set("login_pass", c.Password)
+
+	status, b, err := c.get(ctx, "/aj.html?"+form.Encode())
+	if err != nil {
+		return fmt.Errorf("unable to log in: %w", err)
+	}
+
+	if !loggedIn(status, b) {
+		return &AuthError{Address: c.Address, Username: c.Username}
+	}
+
+	if status/100 != 2 {
+		return fmt.Errorf("unable to log in: %v response received. body: %s", status, b)
+	}
+
+	return nil
+}
+
+// getPage gets page and unmarshals it into structToFill
+func (c *ajClient) getPage(ctx context.Context, page ajPage, structToFill interface{}) error {
+	b, err := c.do(ctx, "/aj.html?a="+url.QueryEscape(string(page)))
+	if err != nil {
+		return fmt.Errorf("unable to get page %s on %s: %w", page, c.Address, err)
+	}
+
+	err = json.Unmarshal(b, structToFill)
+	if err != nil {
+		return fmt.Errorf("unable to get page %s on %s: invalid body: %w", page, c.Address, err)
+	}
+
+	return nil
+}
+
+// ajCommandFailedRegex matches the whole reply the switcher sends when it doesn't accept a command ("Command FAILED")
+var ajCommandFailedRegex = regexp.MustCompile(`(?i)^command\s+failed$`)
+
+// sendCommand sends command to the switcher. if the switcher replies that the command failed, a *DeviceError is returned
+func (c *ajClient) sendCommand(ctx context.Context, command ajCommand) error {
+	b, err := c.do(ctx, "/aj.html?a=command&cmd="+url.QueryEscape(string(command)))
+	if err != nil {
+		return fmt.Errorf("unable to send command '%s' to %s: %w", command, c.Address, err)
+	}
+
+	if reply := strings.TrimSpace(string(b)); ajCommandFailedRegex.MatchString(reply) {
+		return fmt.Errorf("unable to send command '%s' to %s: %w", command, c.Address, &DeviceError{Address: c.Address, Message: reply})
+	}
+
+	return nil
+}
+
+// getRoot gets the switcher's main web page
+func (c *ajClient) getRoot(ctx context.Context) ([]byte, error) {
+	b, err := c.do(ctx, "/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get main page on %s: %w", c.Address, err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,211 @@
+package atlona
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ajLoginPage = `<html><body><form action="aj.html"><input type="text" name="login_user"><input type="password" name="login_pass"></form></body></html>`
+
+// ajSwitcher is a fake aj.html switcher that sends its login page until it gets a login
+type ajSwitcher struct {
+	loggedIn bool
+	logins   int
+	commands []string
+
+	// commandReply is the fixture sent in reply to every command
+	commandReply string
+}
+
+// reply returns the command reply fixture, or s.commandReply if it is set
+func (s *ajSwitcher) reply(fixture string) []byte {
+	if s.commandReply != "" {
+		fixture = s.commandReply
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "ajhtml", fixture))
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	return b
+}
+
+func (s *ajSwitcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("a") == "login" {
+		if q.Get("login_user") != "admin" || q.Get("login_pass") != "secret" {
+			w.Write([]byte(`{"login_ur":0}`))
+			return
+		}
+
+		s.loggedIn = true
+		s.logins++
+		w.Write([]byte(`{"login_ur":1}`))
+		return
+	}
+
+	if !s.loggedIn {
+		w.Write([]byte(ajLoginPage))
+		return
+	}
+
+	switch q.Get("a") {
+	case "command":
+		if !strings.HasPrefix(q.Get("cmd"), "x") {
+			w.Write(s.reply("command-failed.txt"))
+			return
+		}
+
+		s.commands = append(s.commands, q.Get("cmd"))
+		w.Write(s.reply("command-ok.txt"))
+	case string(avSettingsPage):
+		w.Write([]byte(`{"login_ur":1,"inp":2}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAJClientLoginPage(t *testing.T) {
+	sw := &ajSwitcher{}
+	server := httptest.NewServer(sw)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := &ajClient{Address: strings.TrimPrefix(server.URL, "http://"), Username: "admin", Password: "secret"}
+
+	if err := c.sendCommand(ctx, "x1AVx1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if sw.logins != 1 || len(sw.commands) != 1 || sw.commands[0] != "x1AVx1" {
+		t.Fatalf("got %v logins and commands %v, expected 1 login and [x1AVx1]", sw.logins, sw.commands)
+	}
+
+	// the session expires on the switcher
+	sw.loggedIn = false
+
+	var resp wallPlateStruct
+	if err := c.getPage(ctx, avSettingsPage, &resp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if sw.logins != 2 || resp.Inp != 2 {
+		t.Errorf("got %v logins and input %v, expected 2 logins and input 2", sw.logins, resp.Inp)
+	}
+}
+
+func TestAJClientAuthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		handler  http.HandlerFunc
+	}{
+		{
+			name: "401 without credentials",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+		},
+		{
+			name: "login page without credentials",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(ajLoginPage))
+			},
+		},
+		{
+			name:     "wrong password",
+			username: "admin",
+			handler:  (&ajSwitcher{}).ServeHTTP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			c := &ajClient{Address: strings.TrimPrefix(server.URL, "http://"), Username: tt.username, Password: "wrong"}
+
+			var authErr *AuthError
+			if err := c.sendCommand(ctx, "x1AVx1"); !errors.As(err, &authErr) {
+				t.Fatalf("got error %v, expected an *AuthError", err)
+			}
+		})
+	}
+}
+
+func TestAJClientCommandReply(t *testing.T) {
+	tests := []struct {
+		fixture string
+		failed  bool
+	}{
+		{fixture: "command-failed.txt", failed: true},
+		{fixture: "command-ok.txt"},
+		{fixture: "command-ok-echo.txt"},
+		{fixture: "command-ok-page.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			server := httptest.NewServer(&ajSwitcher{loggedIn: true, commandReply: tt.fixture})
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			c := &ajClient{Address: strings.TrimPrefix(server.URL, "http://")}
+
+			err := c.sendCommand(ctx, "x1AVx1")
+
+			var devErr *DeviceError
+			if failed := errors.As(err, &devErr); failed != tt.failed {
+				t.Errorf("got error %v, expected *DeviceError to be %v", err, tt.failed)
+			}
+
+			if !tt.failed && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestAJClientFollowsSwitcherFields(t *testing.T) {
+	first, second := &ajSwitcher{}, &ajSwitcher{}
+
+	server1 := httptest.NewServer(first)
+	defer server1.Close()
+
+	server2 := httptest.NewServer(second)
+	defer server2.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vs := &AtlonaVideoSwitcher2x1{Address: strings.TrimPrefix(server1.URL, "http://"), Username: "admin", Password: "secret"}
+	if err := vs.SetAudioVideoInput(ctx, "1", "1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	vs.Address = strings.TrimPrefix(server2.URL, "http://")
+	if err := vs.SetAudioVideoInput(ctx, "1", "2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(first.commands) != 1 || len(second.commands) != 1 || second.logins != 1 {
+		t.Errorf("got commands %v and %v (%v logins on the second switcher), expected one command on each", first.commands, second.commands, second.logins)
+	}
+}
@@ -0,0 +1,334 @@
+package atlona
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the GAIN-60 web server doesn't send conforming HTTP responses (missing reason phrases,
+// bare LF line endings, junk header lines, wrong content lengths), which makes net/http
+// give up on them. ampGet talks to the amp over a plain tcp connection instead and
+// parses whatever comes back as leniently as possible.
+
+const (
+	ampTimeout         = 10 * time.Second
+	ampMaxResponseSize = 1 << 20
+)
+
+// ampGet performs a GET request for ampURL and returns the status code and body of the response
+func ampGet(ctx context.Context, ampURL string) (int, []byte, error) {
+	u, err := url.Parse(ampURL)
+	if err != nil {
+		// the url may have credentials in it, so leave it out of the error
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+
+		return 0, nil, fmt.Errorf("unable to parse url %s: %w", redactAmpURL(ampURL), err)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	dialer := net.Dialer{Timeout: ampTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to connect to %s: %w", host, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(ampTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return 0, nil, fmt.Errorf("unable to set deadline: %w", err)
+	}
+
+	// unblock reads/writes if the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	req := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nAccept: application/json\r\nConnection: close\r\n\r\n", u.RequestURI(), u.Host)
+	if _, err := io.WriteString(conn, req); err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, fmt.Errorf("unable to write request: %w", ctx.Err())
+		}
+
+		return 0, nil, fmt.Errorf("unable to write request: %w", err)
+	}
+
+	raw, err := readAmpResponse(conn)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, fmt.Errorf("unable to read response: %w", ctx.Err())
+		}
+
+		return 0, nil, fmt.Errorf("unable to read response: %w", err)
+	}
+
+	return parseAmpResponse(raw)
+}
+
+// readAmpResponse reads from r until a whole response has arrived. The amp doesn't always close the
+// connection, so reading stops as soon as the response is complete (see ampResponseComplete). io.EOF
+// also ends the response, since the amp's content lengths can't be trusted; any other error is returned.
+func readAmpResponse(r io.Reader) ([]byte, error) {
+	var raw []byte
+	buf := make([]byte, 4096)
+
+	for {
+		n, err := r.Read(buf)
+		raw = append(raw, buf[:n]...)
+
+		if ampResponseComplete(raw) {
+			return raw, nil
+		}
+
+		switch {
+		case err == io.EOF:
+			if len(bytes.TrimSpace(raw)) == 0 {
+				return nil, fmt.Errorf("connection closed before a response was sent")
+			}
+
+			return raw, nil
+		case err != nil:
+			return nil, err
+		case len(raw) > ampMaxResponseSize:
+			return nil, fmt.Errorf("response is larger than %v bytes", ampMaxResponseSize)
+		}
+	}
+}
+
+// ampResponseComplete reports whether raw holds a whole response: the headers and Content-Length bytes
+// of body, the last chunk of a chunked body, or (if there is no length, or it would cut the json body
+// short) a complete json body
+func ampResponseComplete(raw []byte) bool {
+	trimmed := bytes.TrimLeft(raw, " \t\r\n\x00")
+	if len(trimmed) == 0 {
+		return false
+	}
+
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return json.Valid(trimAmpBody(trimmed))
+	}
+
+	head, body, ok := splitAmpHead(trimmed)
+	if !ok {
+		return false
+	}
+
+	_, headers := parseAmpHead(head)
+
+	if strings.Contains(strings.ToLower(headers["transfer-encoding"]), "chunked") {
+		return bytes.HasPrefix(body, []byte("0\r\n")) || bytes.HasPrefix(body, []byte("0\n")) ||
+			bytes.Contains(body, []byte("\n0\r\n\r\n")) || bytes.Contains(body, []byte("\n0\n\n"))
+	}
+
+	if cl, err := strconv.Atoi(headers["content-length"]); err == nil && cl >= 0 {
+		if len(body) < cl {
+			return false
+		}
+
+		if !ampLengthCutsJSON(body, cl) {
+			return true
+		}
+
+		// the content length is too short for the json body, so wait for the whole thing
+	}
+
+	body = trimAmpBody(body)
+	return len(body) > 0 && json.Valid(body)
+}
+
+// ampLengthCutsJSON reports whether cutting body off at cl bytes would end it in the middle of a json value
+func ampLengthCutsJSON(body []byte, cl int) bool {
+	if cl >= len(body) {
+		return false
+	}
+
+	trimmed := trimAmpBody(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+
+	return !json.Valid(trimAmpBody(body[:cl]))
+}
+
+// parseAmpResponse parses a raw HTTP/1.x response from the amp, tolerating the ways the amp breaks the spec
+func parseAmpResponse(raw []byte) (int, []byte, error) {
+	raw = bytes.TrimLeft(raw, " \t\r\n\x00")
+	if len(raw) == 0 {
+		return 0, nil, fmt.Errorf("empty response")
+	}
+
+	// no status line or headers at all, just a body
+	if raw[0] == '{' || raw[0] == '[' {
+		return 200, trimAmpBody(raw), nil
+	}
+
+	head, body := splitAmpResponse(raw)
+	status, headers := parseAmpHead(head)
+
+	if strings.Contains(strings.ToLower(headers["transfer-encoding"]), "chunked") {
+		if decoded, err := decodeAmpChunked(body); err == nil {
+			body = decoded
+		}
+	} else if cl, err := strconv.Atoi(headers["content-length"]); err == nil && cl >= 0 && cl < len(body) {
+		// only trust the content length if it doesn't cut the json body in half
+		if !ampLengthCutsJSON(body, cl) {
+			body = body[:cl]
+		} else if !json.Valid(trimAmpBody(body)) {
+			return 0, nil, fmt.Errorf("content length %v is shorter than the body, and the whole body is not valid json: %s", cl, body)
+		}
+	}
+
+	return status, trimAmpBody(body), nil
+}
+
+// parseAmpHead parses the status line (if there is one) and headers of a response
+func parseAmpHead(head []byte) (int, map[string]string) {
+	lines := strings.Split(strings.Replace(string(head), "\r\n", "\n", -1), "\n")
+
+	status := 200
+	if code, ok := parseAmpStatusLine(lines[0]); ok {
+		status = code
+		lines = lines[1:]
+	}
+
+	headers := make(map[string]string)
+	for _, line := range lines {
+		idx := strings.Index(line, ":")
+		if idx <= 0 {
+			// junk header line, skip it
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		headers[key] = strings.TrimSpace(line[idx+1:])
+	}
+
+	return status, headers
+}
+
+// splitAmpHead splits a response at the first blank line, if there is one
+func splitAmpHead(raw []byte) ([]byte, []byte, bool) {
+	crlf := bytes.Index(raw, []byte("\r\n\r\n"))
+	lf := bytes.Index(raw, []byte("\n\n"))
+
+	switch {
+	case crlf >= 0 && (lf < 0 || crlf < lf):
+		return raw[:crlf], raw[crlf+4:], true
+	case lf >= 0:
+		return raw[:lf], raw[lf+2:], true
+	}
+
+	return raw, nil, false
+}
+
+// splitAmpResponse splits a response at the first blank line, accepting either CRLF or bare LF
+func splitAmpResponse(raw []byte) ([]byte, []byte) {
+	if head, body, ok := splitAmpHead(raw); ok {
+		return head, body
+	}
+
+	// no body separator; if there is json on the end of the headers, use that as the body
+	if idx := bytes.IndexAny(raw, "{["); idx >= 0 {
+		return raw[:idx], raw[idx:]
+	}
+
+	return raw, nil
+}
+
+// parseAmpStatusLine pulls the status code out of the status line, ignoring the protocol version and reason phrase
+func parseAmpStatusLine(line string) (int, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0, false
+	}
+
+	if !strings.HasPrefix(strings.ToUpper(fields[0]), "HTTP") {
+		// some responses skip the protocol version entirely
+		if code, err := strconv.Atoi(fields[0]); err == nil && code >= 100 && code <= 999 {
+			return code, true
+		}
+
+		return 0, false
+	}
+
+	for _, field := range fields[1:] {
+		if code, err := strconv.Atoi(field); err == nil && code >= 100 && code <= 999 {
+			return code, true
+		}
+	}
+
+	// just "HTTP/1.1" with no code
+	return 200, true
+}
+
+func decodeAmpChunked(body []byte) ([]byte, error) {
+	var out bytes.Buffer
+	r := bufio.NewReader(bytes.NewReader(body))
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			return out.Bytes(), nil
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if idx := strings.Index(line, ";"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		size, perr := strconv.ParseInt(line, 16, 64)
+		if perr != nil {
+			return nil, fmt.Errorf("invalid chunk size %q: %w", line, perr)
+		}
+
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&out, r, size); err != nil {
+			// short last chunk, keep what we got
+			return out.Bytes(), nil
+		}
+
+		// trailing CRLF (or LF) after the chunk data
+		if b, err := r.Peek(1); err == nil && b[0] == '\r' {
+			_, _ = r.Discard(1)
+		}
+		if b, err := r.Peek(1); err == nil && b[0] == '\n' {
+			_, _ = r.Discard(1)
+		}
+	}
+}
+
+func trimAmpBody(body []byte) []byte {
+	return bytes.Trim(body, " \t\r\n\x00")
+}
@@ -0,0 +1,218 @@
+package atlona
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var ampResponseTests = []struct {
+	fixture string
+	status  int
+	body    string
+}{
+	{"no-reason-phrase.http", 200, `{"608":"40","609":"0"}`},
+	{"bare-lf.http", 200, `{"608":"-12","609":"1"}`},
+	{"junk-header.http", 200, `{"101":"AT-GAIN-60","102":"1.0.13","103":"b8:98:b0:01:02:03","104":"1234567890","105":"00d 05h 12m"}`},
+	{"long-content-length.http", 200, `{"608":"30","609":"0"}`},
+	{"short-content-length.http", 200, `{"608":"40","609":"0"}`},
+	{"chunked.http", 200, `{"608":"55","609":"0"}`},
+	{"no-status-line.http", 200, `{"Login":true}`},
+	{"body-only.http", 200, `{"Login":false}`},
+	{"no-separator.http", 200, `{"608":"20","609":"1"}`},
+	{"not-found.http", 404, `<html><body>404</body></html>`},
+}
+
+func readAmpFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "amp60", name))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %s", err)
+	}
+
+	return b
+}
+
+func TestParseAmpResponse(t *testing.T) {
+	for _, tt := range ampResponseTests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			status, body, err := parseAmpResponse(readAmpFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if status != tt.status {
+				t.Errorf("got status %v, expected %v", status, tt.status)
+			}
+
+			if string(body) != tt.body {
+				t.Errorf("got body %q, expected %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseAmpResponseEmpty(t *testing.T) {
+	if _, _, err := parseAmpResponse([]byte("\r\n")); err == nil {
+		t.Fatalf("expected an error for an empty response")
+	}
+}
+
+func TestParseAmpResponseShortContentLength(t *testing.T) {
+	// the content length cuts the json in half, and so does the end of the body
+	raw := []byte("HTTP/1.1 200 OK\r\nContent-Length: 12\r\n\r\n{\"608\":\"40\",\"6")
+
+	if _, body, err := parseAmpResponse(raw); err == nil {
+		t.Fatalf("expected an error, got body %q", body)
+	}
+}
+
+// serveAmpFixture writes the fixture to the first connection it receives, then closes it
+func serveAmpFixture(t *testing.T, raw []byte) string {
+	t.Helper()
+	return serveAmpFixtureOpen(t, raw, 0)
+}
+
+// serveAmpFixtureOpen writes the fixture to the first connection it receives, and holds the connection open for hold before closing it
+func serveAmpFixtureOpen(t *testing.T, raw []byte, hold time.Duration) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+
+	go func() {
+		defer l.Close()
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// read the request before responding
+		buf := make([]byte, 1024)
+		_, _ = conn.Read(buf)
+		_, _ = conn.Write(raw)
+
+		time.Sleep(hold)
+	}()
+
+	return l.Addr().String()
+}
+
+func TestAmpGet(t *testing.T) {
+	addr := serveAmpFixture(t, readAmpFixture(t, "junk-header.http"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, body, err := ampGet(ctx, fmt.Sprintf("http://%s/action=devicestatus_get&r=0.5", addr))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if status != 200 {
+		t.Errorf("got status %v, expected 200", status)
+	}
+
+	if string(body) != ampResponseTests[2].body {
+		t.Errorf("got body %q, expected %q", body, ampResponseTests[2].body)
+	}
+}
+
+func TestAmpGetCancelled(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer l.Close()
+
+	// accept the connection but never respond
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		time.Sleep(2 * time.Second)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := ampGet(ctx, fmt.Sprintf("http://%s/action=deviceaudio_get", l.Addr())); err == nil {
+		t.Fatalf("expected an error when the context is cancelled")
+	}
+}
+
+var ampResponseCompleteTests = map[string]bool{
+	"no-reason-phrase.http":     true,
+	"bare-lf.http":              true,
+	"junk-header.http":          true,
+	"long-content-length.http":  false, // the content length is longer than the body
+	"short-content-length.http": true,  // the content length is shorter than the json body, which is complete
+	"chunked.http":              true,
+	"no-status-line.http":       true,
+	"body-only.http":            true,
+	"no-separator.http":         false,
+	"not-found.http":            false,
+}
+
+func TestAmpResponseComplete(t *testing.T) {
+	for fixture, complete := range ampResponseCompleteTests {
+		t.Run(fixture, func(t *testing.T) {
+			raw := readAmpFixture(t, fixture)
+
+			if got := ampResponseComplete(raw); got != complete {
+				t.Errorf("got %v, expected %v", got, complete)
+			}
+
+			if ampResponseComplete(raw[:len(raw)-4]) {
+				t.Errorf("truncated response was reported as complete")
+			}
+		})
+	}
+}
+
+func TestAmpGetKeepAlive(t *testing.T) {
+	// the amp sends the whole response but leaves the connection open
+	addr := serveAmpFixtureOpen(t, readAmpFixture(t, "no-reason-phrase.http"), 3*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	status, body, err := ampGet(ctx, fmt.Sprintf("http://%s/action=deviceaudio_get&r=0.5", addr))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to read a complete response", elapsed)
+	}
+
+	if status != 200 || string(body) != ampResponseTests[0].body {
+		t.Errorf("got %v %q, expected 200 %q", status, body, ampResponseTests[0].body)
+	}
+}
+
+func TestAmpGetTruncated(t *testing.T) {
+	// the amp sends part of the body, then stops responding
+	raw := []byte("HTTP/1.1 200 OK\r\nContent-Length: 23\r\n\r\n{\"608\":\"40\",")
+	addr := serveAmpFixtureOpen(t, raw, 2*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, body, err := ampGet(ctx, fmt.Sprintf("http://%s/action=deviceaudio_get&r=0.5", addr)); err == nil {
+		t.Fatalf("expected an error for a truncated response, got body %q", body)
+	}
+}
@@ -0,0 +1,372 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AmpValueType is the type of the value stored in an amp register
+type AmpValueType int
+
+// Value types for amp registers
+const (
+	AmpString AmpValueType = iota
+	AmpInt
+	AmpBool
+)
+
+func (t AmpValueType) String() string {
+	switch t {
+	case AmpString:
+		return "string"
+	case AmpInt:
+		return "int"
+	case AmpBool:
+		return "bool"
+	default:
+		return fmt.Sprintf("AmpValueType(%d)", int(t))
+	}
+}
+
+// AmpRegister describes one of the numeric keys in the GAIN-60 web protocol.
+// Registers are read with action=<Endpoint>_get and written with action=<Endpoint>_set&<Key>=<value>.
+type AmpRegister struct {
+	Key      int
+	Name     string
+	Endpoint string
+	Type     AmpValueType
+
+	// Min and Max bound the value of AmpInt registers. If both are zero, the value isn't checked.
+	Min int
+	Max int
+
+	Writable bool
+}
+
+// Registers known to exist on the amp
+var (
+	AmpRegModel         = AmpRegister{Key: 101, Name: "model", Endpoint: "devicestatus", Type: AmpString}
+	AmpRegFirmware      = AmpRegister{Key: 102, Name: "firmware", Endpoint: "devicestatus", Type: AmpString}
+	AmpRegMACAddress    = AmpRegister{Key: 103, Name: "mac address", Endpoint: "devicestatus", Type: AmpString}
+	AmpRegSerialNumber  = AmpRegister{Key: 104, Name: "serial number", Endpoint: "devicestatus", Type: AmpString}
+	AmpRegOperatingTime = AmpRegister{Key: 105, Name: "operating time", Endpoint: "devicestatus", Type: AmpString}
+
+	AmpRegInput          = AmpRegister{Key: 501, Name: "input", Endpoint: "devicesetting", Type: AmpInt, Min: int(AmpInputAnalog), Max: int(AmpInputDigital), Writable: true}
+	AmpRegAutoSense      = AmpRegister{Key: 502, Name: "auto sense", Endpoint: "devicesetting", Type: AmpBool, Writable: true}
+	AmpRegAnalogSignal   = AmpRegister{Key: 503, Name: "analog signal", Endpoint: "devicesetting", Type: AmpBool}
+	AmpRegDigitalSignal  = AmpRegister{Key: 504, Name: "digital signal", Endpoint: "devicesetting", Type: AmpBool}
+	AmpRegStandbyTimeout = AmpRegister{Key: 505, Name: "auto standby", Endpoint: "devicesetting", Type: AmpInt, Min: 0, Max: int(ampMaxAutoStandby / time.Minute), Writable: true}
+	AmpRegWakeDelay      = AmpRegister{Key: 506, Name: "auto wake", Endpoint: "devicesetting", Type: AmpInt, Min: 0, Max: int(ampMaxAutoWake / time.Second), Writable: true}
+	AmpRegPower          = AmpRegister{Key: 507, Name: "power", Endpoint: "devicesetting", Type: AmpBool, Writable: true}
+
+	AmpRegDHCP    = AmpRegister{Key: 201, Name: "dhcp", Endpoint: "devicenetwork", Type: AmpBool, Writable: true}
+	AmpRegIP      = AmpRegister{Key: 202, Name: "ip address", Endpoint: "devicenetwork", Type: AmpString, Writable: true}
+	AmpRegNetmask = AmpRegister{Key: 203, Name: "netmask", Endpoint: "devicenetwork", Type: AmpString, Writable: true}
+	AmpRegGateway = AmpRegister{Key: 204, Name: "gateway", Endpoint: "devicenetwork", Type: AmpString, Writable: true}
+	AmpRegDNS     = AmpRegister{Key: 205, Name: "dns server", Endpoint: "devicenetwork", Type: AmpString, Writable: true}
+
+	AmpRegVolume   = AmpRegister{Key: 608, Name: "volume", Endpoint: "deviceaudio", Type: AmpInt, Min: 0, Max: 100, Writable: true}
+	AmpRegMute     = AmpRegister{Key: 609, Name: "mute", Endpoint: "deviceaudio", Type: AmpBool, Writable: true}
+	AmpRegBass     = AmpRegister{Key: 610, Name: "bass", Endpoint: "deviceaudio", Type: AmpInt, Min: -12, Max: 12, Writable: true}
+	AmpRegTreble   = AmpRegister{Key: 611, Name: "treble", Endpoint: "deviceaudio", Type: AmpInt, Min: -12, Max: 12, Writable: true}
+	AmpRegBalance  = AmpRegister{Key: 612, Name: "balance", Endpoint: "deviceaudio", Type: AmpInt, Min: -10, Max: 10, Writable: true}
+	AmpRegLoudness = AmpRegister{Key: 613, Name: "loudness", Endpoint: "deviceaudio", Type: AmpBool, Writable: true}
+	AmpRegEQPreset = AmpRegister{Key: 614, Name: "eq preset", Endpoint: "deviceaudio", Type: AmpInt, Min: int(EQFlat), Max: int(EQCustom), Writable: true}
+
+	// the admin account can only be written, so these aren't in AmpRegisters
+	AmpRegUsername = AmpRegister{Key: 701, Name: "username", Endpoint: "deviceaccount", Type: AmpString, Writable: true}
+	AmpRegPassword = AmpRegister{Key: 702, Name: "password", Endpoint: "deviceaccount", Type: AmpString, Writable: true}
+)
+
+// AmpRegisters is every register known to exist on the amp
+var AmpRegisters = []AmpRegister{
+	AmpRegModel,
+	AmpRegFirmware,
+	AmpRegMACAddress,
+	AmpRegSerialNumber,
+	AmpRegOperatingTime,
+	AmpRegInput,
+	AmpRegAutoSense,
+	AmpRegAnalogSignal,
+	AmpRegDigitalSignal,
+	AmpRegStandbyTimeout,
+	AmpRegWakeDelay,
+	AmpRegPower,
+	AmpRegDHCP,
+	AmpRegIP,
+	AmpRegNetmask,
+	AmpRegGateway,
+	AmpRegDNS,
+	AmpRegVolume,
+	AmpRegMute,
+	AmpRegBass,
+	AmpRegTreble,
+	AmpRegBalance,
+	AmpRegLoudness,
+	AmpRegEQPreset,
+}
+
+// LookupAmpRegister finds the register in AmpRegisters with the given key
+func LookupAmpRegister(key int) (AmpRegister, bool) {
+	for _, reg := range AmpRegisters {
+		if reg.Key == key {
+			return reg, true
+		}
+	}
+
+	return AmpRegister{}, false
+}
+
+// Parse converts a raw value from the amp into a string, int, or bool depending on the register's type
+func (r AmpRegister) Parse(raw string) (interface{}, error) {
+	switch r.Type {
+	case AmpInt:
+		val, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s", raw, r.Name)
+		}
+
+		return val, nil
+	case AmpBool:
+		switch strings.TrimSpace(raw) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid value %q for %s", raw, r.Name)
+		}
+	default:
+		return raw, nil
+	}
+}
+
+// Format validates value against the register and converts it into the amp's representation
+func (r AmpRegister) Format(value interface{}) (string, error) {
+	switch r.Type {
+	case AmpInt:
+		val, ok := value.(int)
+		if !ok {
+			return "", fmt.Errorf("%s must be an int, got %T", r.Name, value)
+		}
+
+		if (r.Min != 0 || r.Max != 0) && (val < r.Min || val > r.Max) {
+			return "", fmt.Errorf("%s: %v is out of range [%v, %v]", r.Name, val, r.Min, r.Max)
+		}
+
+		return strconv.Itoa(val), nil
+	case AmpBool:
+		val, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("%s must be a bool, got %T", r.Name, value)
+		}
+
+		if val {
+			return "1", nil
+		}
+
+		return "0", nil
+	default:
+		val, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string, got %T", r.Name, value)
+		}
+
+		return val, nil
+	}
+}
+
+// readEndpoint gets every key returned by the endpoint, as the raw strings sent by the amp
+func (a *Amp60) readEndpoint(ctx context.Context, endpoint string) (map[int]string, error) {
+	resp, err := a.sendReq(ctx, endpoint+"_get")
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(resp, &fields); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal %s response: %w", endpoint, err)
+	}
+
+	vals := make(map[int]string, len(fields))
+	for k, v := range fields {
+		key, err := strconv.Atoi(k)
+		if err != nil {
+			// not a register
+			continue
+		}
+
+		var str string
+		if err := json.Unmarshal(v, &str); err != nil {
+			// not a string, so keep it as it was sent (a bare number, etc)
+			str = string(v)
+		}
+
+		vals[key] = str
+	}
+
+	return vals, nil
+}
+
+func (a *Amp60) readRegisters(ctx context.Context, regs ...AmpRegister) (map[int]interface{}, error) {
+	raw := make(map[string]map[int]string)
+	vals := make(map[int]interface{}, len(regs))
+
+	for _, reg := range regs {
+		if _, ok := raw[reg.Endpoint]; !ok {
+			fields, err := a.readEndpoint(ctx, reg.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+
+			raw[reg.Endpoint] = fields
+		}
+
+		str, ok := raw[reg.Endpoint][reg.Key]
+		if !ok {
+			return nil, fmt.Errorf("%s (%v) was not returned by %s", reg.Name, reg.Key, reg.Endpoint)
+		}
+
+		val, err := reg.Parse(str)
+		if err != nil {
+			return nil, err
+		}
+
+		vals[reg.Key] = val
+	}
+
+	return vals, nil
+}
+
+// GetRegister reads the value of reg from the amp. The value is a string, int, or bool depending on reg.Type
+func (a *Amp60) GetRegister(ctx context.Context, reg AmpRegister) (interface{}, error) {
+	vals, err := a.readRegisters(ctx, reg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s: %w", reg.Name, err)
+	}
+
+	return vals[reg.Key], nil
+}
+
+// SetRegister validates value against reg and writes it to the amp
+func (a *Amp60) SetRegister(ctx context.Context, reg AmpRegister, value interface{}) error {
+	if err := a.writeRegisters(ctx, []AmpRegister{reg}, []interface{}{value}); err != nil {
+		return fmt.Errorf("unable to set %s: %w", reg.Name, err)
+	}
+
+	return nil
+}
+
+// writeRegisters writes all of the values in one request. every register must be on the same endpoint
+func (a *Amp60) writeRegisters(ctx context.Context, regs []AmpRegister, vals []interface{}) error {
+	if len(regs) == 0 || len(regs) != len(vals) {
+		return fmt.Errorf("mismatched registers and values")
+	}
+
+	var b strings.Builder
+	b.WriteString(regs[0].Endpoint + "_set")
+
+	for i, reg := range regs {
+		if reg.Endpoint != regs[0].Endpoint {
+			return fmt.Errorf("%s is on %s, not %s", reg.Name, reg.Endpoint, regs[0].Endpoint)
+		}
+
+		if !reg.Writable {
+			return fmt.Errorf("register %v (%s) is read only", reg.Key, reg.Name)
+		}
+
+		str, err := reg.Format(vals[i])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&b, "&%v=%s", reg.Key, url.QueryEscape(str))
+	}
+
+	_, err := a.sendReq(ctx, b.String())
+	return err
+}
+
+func (a *Amp60) getRegisterInt(ctx context.Context, reg AmpRegister) (int, error) {
+	val, err := a.GetRegister(ctx, reg)
+	if err != nil {
+		return 0, err
+	}
+
+	i, ok := val.(int)
+	if !ok {
+		return 0, fmt.Errorf("unable to get %s: register %v is a %s", reg.Name, reg.Key, reg.Type)
+	}
+
+	return i, nil
+}
+
+func (a *Amp60) getRegisterBool(ctx context.Context, reg AmpRegister) (bool, error) {
+	val, err := a.GetRegister(ctx, reg)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("unable to get %s: register %v is a %s", reg.Name, reg.Key, reg.Type)
+	}
+
+	return b, nil
+}
+
+// AmpSnapshot is the value of every register on the amp, keyed by register key.
+// Known registers hold typed values; keys that aren't in AmpRegisters hold the raw string from the amp.
+type AmpSnapshot map[int]interface{}
+
+// Keys returns the keys in the snapshot in ascending order
+func (s AmpSnapshot) Keys() []int {
+	keys := make([]int, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+	return keys
+}
+
+// Snapshot reads every endpoint in AmpRegisters once and returns all of the values the amp reports
+func (a *Amp60) Snapshot(ctx context.Context) (AmpSnapshot, error) {
+	snapshot := make(AmpSnapshot)
+	done := make(map[string]bool)
+
+	for _, reg := range AmpRegisters {
+		if done[reg.Endpoint] {
+			continue
+		}
+		done[reg.Endpoint] = true
+
+		fields, err := a.readEndpoint(ctx, reg.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get snapshot: %w", err)
+		}
+
+		for key, raw := range fields {
+			known, ok := LookupAmpRegister(key)
+			if !ok {
+				snapshot[key] = raw
+				continue
+			}
+
+			val, err := known.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get snapshot: %w", err)
+			}
+
+			snapshot[key] = val
+		}
+	}
+
+	return snapshot, nil
+}
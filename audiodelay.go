@@ -0,0 +1,18 @@
+package atlona
+
+import "context"
+
+// AudioDelayer is implemented by devices that can delay their audio outputs to line up with the video (lip sync).
+// Delays are in milliseconds. Devices without an audio delay return an *UnsupportedError.
+type AudioDelayer interface {
+	GetAudioDelays(ctx context.Context, blocks []string) (map[string]int, error)
+	SetAudioDelay(ctx context.Context, output string, delay int) error
+}
+
+var (
+	_ AudioDelayer = &AtlonaVideoSwitcher2x1{}
+	_ AudioDelayer = &AtlonaVideoSwitcher4x1{}
+	_ AudioDelayer = &AtlonaVideoSwitcher5x1{}
+	_ AudioDelayer = &AtlonaVideoSwitcher6x2{}
+	_ AudioDelayer = &Amp60{}
+)
@@ -0,0 +1,45 @@
+package atlona
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthError is returned when a device rejects the configured credentials
+type AuthError struct {
+	Address  string
+	Username string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication to %s as %q failed", e.Address, e.Username)
+}
+
+// DeviceError is returned when a device responds to a request with an error of its own
+type DeviceError struct {
+	Address string
+	Message string
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("%s reported an error: %s", e.Address, e.Message)
+}
+
+// InvalidOutputError is returned when a request names an output that the device doesn't have
+type InvalidOutputError struct {
+	Output string
+	Valid  []string
+}
+
+func (e *InvalidOutputError) Error() string {
+	return fmt.Sprintf("invalid output %q (valid outputs are %s)", e.Output, strings.Join(e.Valid, ", "))
+}
+
+// UnsupportedError is returned when a device doesn't support the requested feature
+type UnsupportedError struct {
+	Feature string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported on this device", e.Feature)
+}
@@ -0,0 +1,225 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/byuoitav/wspool"
+	"github.com/gorilla/websocket"
+)
+
+// rpcDefaultTimeout is how long to wait for the reply to a call if the client's timeout isn't set
+const rpcDefaultTimeout = 5 * time.Second
+
+// rpcNotificationBuffer is how many notifications are held for Notifications() before new ones are dropped
+const rpcNotificationBuffer = 32
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcMessage is any message the device sends: a reply to a request (ID is set), or a notification (ID is not)
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// id returns the message's id as a string, or "" if it doesn't have one
+func (m rpcMessage) id() string {
+	raw := strings.TrimSpace(string(m.ID))
+	if raw == "" || raw == "null" {
+		return ""
+	}
+
+	var id string
+	if err := json.Unmarshal(m.ID, &id); err == nil {
+		return id
+	}
+
+	return raw
+}
+
+// RPCError is a JSON-RPC 2.0 error object returned by the device
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("json-rpc error %d: %s (%s)", e.Code, e.Message, e.Data)
+	}
+
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// Notification is a message the device sent on its own, rather than in reply to a request
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// rpcClient is a JSON-RPC 2.0 client on top of a websocket pool. Every request gets a unique
+// id, and replies are matched to requests by id; replies to other requests are dropped, and
+// notifications are passed on to the notifications channel.
+type rpcClient struct {
+	nextID uint64 // first so it is 64-bit aligned for atomic
+
+	pool          *wspool.Pool
+	logger        wspool.Logger
+	timeout       func() time.Duration
+	notifications chan Notification
+}
+
+// newRPCClient creates an rpcClient that waits up to timeout() for each reply. timeout is called for every
+// request, so the wait can change after the client is created. if timeout is nil or returns 0, rpcDefaultTimeout is used
+func newRPCClient(pool *wspool.Pool, logger wspool.Logger, timeout func() time.Duration) *rpcClient {
+	return &rpcClient{
+		pool:          pool,
+		logger:        logger,
+		timeout:       timeout,
+		notifications: make(chan Notification, rpcNotificationBuffer),
+	}
+}
+
+func (c *rpcClient) infof(format string, a ...interface{}) {
+	if c.logger != nil {
+		c.logger.Infof(format, a...)
+	}
+}
+
+func (c *rpcClient) warnf(format string, a ...interface{}) {
+	if c.logger != nil {
+		c.logger.Warnf(format, a...)
+	}
+}
+
+// replyTimeout returns how long to wait for the reply to a request
+func (c *rpcClient) replyTimeout() time.Duration {
+	if c.timeout != nil {
+		if timeout := c.timeout(); timeout > 0 {
+			return timeout
+		}
+	}
+
+	return rpcDefaultTimeout
+}
+
+func (c *rpcClient) newRequest(method string, params interface{}) rpcRequest {
+	return rpcRequest{
+		JSONRPC: "2.0",
+		ID:      strconv.FormatUint(atomic.AddUint64(&c.nextID, 1), 10),
+		Method:  method,
+		Params:  params,
+	}
+}
+
+func writeRequest(ws *websocket.Conn, req rpcRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s request: %w", req.Method, err)
+	}
+
+	if err := ws.WriteMessage(websocket.TextMessage, b); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
+
+// call sends method with params, waits for the reply with the same id, and unmarshals its result into
+// result (if it isn't nil). If the device replies with an error object, it is returned as an *RPCError.
+func (c *rpcClient) call(ctx context.Context, method string, params, result interface{}) error {
+	req := c.newRequest(method, params)
+
+	var reply rpcMessage
+	err := c.pool.Do(ctx, func(ws *websocket.Conn) error {
+		c.infof("sending %s request %s", req.Method, req.ID)
+
+		if err := writeRequest(ws, req); err != nil {
+			return err
+		}
+
+		deadline := time.Now().Add(c.replyTimeout())
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+
+		if err := ws.SetReadDeadline(deadline); err != nil {
+			return fmt.Errorf("failed to set readDeadline: %w", err)
+		}
+
+		for {
+			msg, err := c.readMessage(ws)
+			if err != nil {
+				return err
+			}
+
+			if id := msg.id(); id == req.ID {
+				reply = msg
+				return nil
+			} else if id != "" {
+				// the reply to an earlier request that gave up waiting
+				c.infof("dropping reply to request %s", id)
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+
+	if reply.Error != nil {
+		return reply.Error
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(reply.Result, result); err != nil {
+			return fmt.Errorf("unable to unmarshal %s result: %w", method, err)
+		}
+	}
+
+	return nil
+}
+
+// readMessage reads the next message from ws. notifications are passed on before being returned
+func (c *rpcClient) readMessage(ws *websocket.Conn) (rpcMessage, error) {
+	var msg rpcMessage
+
+	_, b, err := ws.ReadMessage()
+	if err != nil {
+		return msg, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &msg); err != nil {
+		c.warnf("dropping invalid message: %s", b)
+		return msg, nil
+	}
+
+	if msg.id() == "" && msg.Method != "" {
+		c.notify(Notification{Method: msg.Method, Params: msg.Params})
+	}
+
+	return msg, nil
+}
+
+func (c *rpcClient) notify(n Notification) {
+	select {
+	case c.notifications <- n:
+	default:
+		c.warnf("dropping %s notification: nobody is reading notifications", n.Method)
+	}
+}
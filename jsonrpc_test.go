@@ -0,0 +1,254 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/byuoitav/wspool"
+	"github.com/gorilla/websocket"
+)
+
+// rpcHandler returns the messages a fake device sends back after receiving req
+type rpcHandler func(req rpcRequest) []string
+
+// newTestRPCClient starts a fake JSON-RPC device that answers with handle, and returns a client
+// connected to it and a func that shuts the device down
+func newTestRPCClient(handle rpcHandler, timeout time.Duration) (*rpcClient, func()) {
+	return newTestRPCClientFunc(handle, func() time.Duration {
+		return timeout
+	})
+}
+
+// newTestRPCClientFunc is newTestRPCClient with a timeout that is read on every call
+func newTestRPCClientFunc(handle rpcHandler, timeout func() time.Duration) (*rpcClient, func()) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		for {
+			_, b, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var req rpcRequest
+			if err := json.Unmarshal(b, &req); err != nil {
+				return
+			}
+
+			for _, msg := range handle(req) {
+				if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+
+	var conns []*websocket.Conn
+	closeFunc := func() {
+		for _, ws := range conns {
+			ws.Close()
+		}
+
+		server.Close()
+	}
+
+	pool := &wspool.Pool{
+		NewConnection: func(ctx context.Context) (*websocket.Conn, error) {
+			ws, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				return nil, err
+			}
+
+			conns = append(conns, ws)
+			return ws, nil
+		},
+		TTL:   10 * time.Second,
+		Delay: 10 * time.Millisecond,
+	}
+
+	return newRPCClient(pool, nil, timeout), closeFunc
+}
+
+func rpcResult(id, result string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%q,"result":%s}`, id, result)
+}
+
+func rpcNotification(method string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":%q,"params":{"source":"input 2"}}`, method)
+}
+
+func TestRPCCallMatchesID(t *testing.T) {
+	c, closeFunc := newTestRPCClient(func(req rpcRequest) []string {
+		return []string{
+			// the reply to a request that already gave up, and a notification, both before the real reply
+			rpcResult("stale", `{"value":"old"}`),
+			rpcNotification("source_changed"),
+			`not json`,
+			rpcResult(req.ID, `{"value":"`+req.Method+`"}`),
+		}
+	}, time.Second)
+	defer closeFunc()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, method := range []string{"config_get", "config_set"} {
+		var result struct {
+			Value string `json:"value"`
+		}
+
+		if err := c.call(ctx, method, nil, &result); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if result.Value != method {
+			t.Errorf("got result %q, expected %q", result.Value, method)
+		}
+	}
+
+	if len(c.notifications) != 2 {
+		t.Fatalf("got %v notifications, expected 2", len(c.notifications))
+	}
+
+	if n := <-c.notifications; n.Method != "source_changed" || !strings.Contains(string(n.Params), "input 2") {
+		t.Errorf("got notification %s %s", n.Method, n.Params)
+	}
+}
+
+func TestRPCNotificationsDroppedWhenFull(t *testing.T) {
+	c, closeFunc := newTestRPCClient(func(req rpcRequest) []string {
+		var msgs []string
+		for i := 0; i < rpcNotificationBuffer+5; i++ {
+			msgs = append(msgs, rpcNotification(fmt.Sprintf("event%v", i)))
+		}
+
+		return append(msgs, rpcResult(req.ID, `null`))
+	}, time.Second)
+	defer closeFunc()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.call(ctx, "config_get", nil, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(c.notifications) != rpcNotificationBuffer {
+		t.Fatalf("got %v notifications, expected %v", len(c.notifications), rpcNotificationBuffer)
+	}
+
+	// the oldest notifications are kept
+	if n := <-c.notifications; n.Method != "event0" {
+		t.Errorf("got first notification %s, expected event0", n.Method)
+	}
+}
+
+func TestRPCError(t *testing.T) {
+	c, closeFunc := newTestRPCClient(func(req rpcRequest) []string {
+		return []string{
+			fmt.Sprintf(`{"jsonrpc":"2.0","id":%q,"error":{"code":-32601,"message":"Method not found","data":"%s"}}`, req.ID, req.Method),
+		}
+	}, time.Second)
+	defer closeFunc()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := c.call(ctx, "reboot", nil, nil)
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("got error %v, expected an *RPCError", err)
+	}
+
+	if rpcErr.Code != -32601 || rpcErr.Message != "Method not found" || string(rpcErr.Data) != `"reboot"` {
+		t.Errorf("got %+v", rpcErr)
+	}
+}
+
+func TestRPCTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     time.Duration
+	}{
+		{
+			name:    "client timeout",
+			timeout: 100 * time.Millisecond,
+			ctx:     5 * time.Second,
+		},
+		{
+			name:    "context deadline",
+			timeout: 5 * time.Second,
+			ctx:     100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the device never replies
+			c, closeFunc := newTestRPCClient(func(req rpcRequest) []string {
+				return nil
+			}, tt.timeout)
+			defer closeFunc()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.ctx)
+			defer cancel()
+
+			start := time.Now()
+			if err := c.call(ctx, "config_get", nil, nil); err == nil {
+				t.Fatalf("expected an error")
+			}
+
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("call took %v to give up", elapsed)
+			}
+		})
+	}
+}
+
+func TestRPCTimeoutChanged(t *testing.T) {
+	// the device only answers config_get
+	timeout := 5 * time.Second
+	c, closeFunc := newTestRPCClientFunc(func(req rpcRequest) []string {
+		if req.Method != "config_get" {
+			return nil
+		}
+
+		return []string{rpcResult(req.ID, `null`)}
+	}, func() time.Duration {
+		return timeout
+	})
+	defer closeFunc()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.call(ctx, "config_get", nil, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// changing the timeout after the first call is used by the next one
+	timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if err := c.call(ctx, "config_set", nil, nil); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %v to give up, expected the new timeout to be used", elapsed)
+	}
+}
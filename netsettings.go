@@ -0,0 +1,47 @@
+package atlona
+
+import (
+	"fmt"
+	"net"
+)
+
+// validateStaticIPv4 checks that ip, netmask, and gateway make up a usable static
+// configuration, and that each dns server (if any) is a valid address
+func validateStaticIPv4(ip, netmask, gateway string, dns ...string) error {
+	addrs := [][2]string{
+		{"ip address", ip},
+		{"netmask", netmask},
+		{"gateway", gateway},
+	}
+
+	for _, d := range dns {
+		addrs = append(addrs, [2]string{"dns server", d})
+	}
+
+	for _, addr := range addrs {
+		if err := validateIPv4(addr[0], addr[1]); err != nil {
+			return err
+		}
+	}
+
+	mask := net.IPMask(net.ParseIP(netmask).To4())
+	if ones, bits := mask.Size(); ones == 0 && bits == 0 {
+		return fmt.Errorf("invalid netmask %q", netmask)
+	}
+
+	ipnet := net.IPNet{IP: net.ParseIP(ip).Mask(mask), Mask: mask}
+	if !ipnet.Contains(net.ParseIP(gateway)) {
+		return fmt.Errorf("gateway %s is not on %s", gateway, ipnet.String())
+	}
+
+	return nil
+}
+
+// validateIPv4 checks that addr is an IPv4 address. name describes addr in the error
+func validateIPv4(name, addr string) error {
+	if ip := net.ParseIP(addr); ip == nil || ip.To4() == nil {
+		return fmt.Errorf("invalid %s %q", name, addr)
+	}
+
+	return nil
+}
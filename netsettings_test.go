@@ -0,0 +1,31 @@
+package atlona
+
+import "testing"
+
+func TestValidateStaticIPv4(t *testing.T) {
+	tests := []struct {
+		name                 string
+		ip, netmask, gateway string
+		dns                  []string
+		err                  bool
+	}{
+		{name: "valid", ip: "10.5.34.60", netmask: "255.255.255.0", gateway: "10.5.34.1", dns: []string{"10.8.0.26"}},
+		{name: "no dns", ip: "192.168.1.20", netmask: "255.255.0.0", gateway: "192.168.0.1"},
+		{name: "ipv6 address", ip: "fe80::1", netmask: "255.255.255.0", gateway: "10.5.34.1", err: true},
+		{name: "bad netmask", ip: "10.5.34.60", netmask: "255.0.255.0", gateway: "10.5.34.1", err: true},
+		{name: "gateway off subnet", ip: "10.5.34.60", netmask: "255.255.255.0", gateway: "10.5.35.1", err: true},
+		{name: "bad dns", ip: "10.5.34.60", netmask: "255.255.255.0", gateway: "10.5.34.1", dns: []string{"dns.byu.edu"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStaticIPv4(tt.ip, tt.netmask, tt.gateway, tt.dns...)
+			switch {
+			case tt.err && err == nil:
+				t.Fatalf("expected an error")
+			case !tt.err && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
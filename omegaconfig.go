@@ -0,0 +1,297 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OmegaConfig is the configuration tree of an Atlona Omega series device, as read and written through
+// /cgi-bin/config.cgi. Every field is a pointer so that partial documents can be built: nil fields are
+// left out of getConfig/setConfig requests, and an empty struct asks for everything below it.
+type OmegaConfig struct {
+	Video   *OmegaVideo   `json:"video,omitempty"`
+	Audio   *OmegaAudio   `json:"audio,omitempty"`
+	Network *OmegaNetwork `json:"network,omitempty"`
+	System  *OmegaSystem  `json:"system,omitempty"`
+}
+
+// OmegaVideo is the video section of the config
+type OmegaVideo struct {
+	VidOut *OmegaVidOut `json:"vidOut,omitempty"`
+	Hdbt   *OmegaHdbt   `json:"hdbt,omitempty"`
+}
+
+// OmegaVidOut is the video outputs
+type OmegaVidOut struct {
+	HdmiOut *OmegaHdmiOut `json:"hdmiOut,omitempty"`
+}
+
+// OmegaHdmiOut is the hdmi outputs
+type OmegaHdmiOut struct {
+	HdmiOutA *OmegaVideoOutput `json:"hdmiOutA,omitempty"`
+	HdmiOutB *OmegaVideoOutput `json:"hdmiOutB,omitempty"`
+	Mirror   *OmegaVideoOutput `json:"mirror,omitempty"`
+}
+
+// OmegaVideoOutput is a single video output
+type OmegaVideoOutput struct {
+	VideoSrc    *int      `json:"videoSrc,omitempty"`
+	Resolution  *string   `json:"resolution,omitempty"`
+	ScalingMode *string   `json:"scalingMode,omitempty"`
+	Cec         *OmegaCec `json:"cec,omitempty"`
+}
+
+// OmegaCec is the HDMI-CEC control of the display on a video output. setting Command sends it to
+// the display; DisplayPower is read only
+type OmegaCec struct {
+	Command      *string `json:"command,omitempty"`
+	DisplayPower *string `json:"displayPower,omitempty"`
+}
+
+// OmegaHdbt is the HDBaseT ports, one for each Valens chip
+type OmegaHdbt struct {
+	HdbtIn1 *OmegaHdbtPort `json:"hdbtIn1,omitempty"`
+	HdbtIn2 *OmegaHdbtPort `json:"hdbtIn2,omitempty"`
+	HdbtOut *OmegaHdbtPort `json:"hdbtOut,omitempty"`
+}
+
+// OmegaHdbtPort is the link status of a single HDBaseT port. it is read only
+type OmegaHdbtPort struct {
+	LinkStatus      *bool `json:"linkStatus,omitempty"`
+	RemoteConnected *bool `json:"remoteConnected,omitempty"`
+	LinkQuality     *int  `json:"linkQuality,omitempty"`
+	ErrorCount      *int  `json:"errorCount,omitempty"`
+	CableLength     *int  `json:"cableLength,omitempty"`
+}
+
+// OmegaAudio is the audio section of the config
+type OmegaAudio struct {
+	AudIn  *OmegaAudIn  `json:"audIn,omitempty"`
+	AudOut *OmegaAudOut `json:"audOut,omitempty"`
+}
+
+// OmegaAudIn is the audio inputs
+type OmegaAudIn struct {
+	Mic *OmegaMic `json:"mic,omitempty"`
+}
+
+// OmegaMic is the mic input
+type OmegaMic struct {
+	Gain         *int  `json:"gain,omitempty"`
+	AudioMute    *bool `json:"audioMute,omitempty"`
+	PhantomPower *bool `json:"phantomPower,omitempty"`
+}
+
+// OmegaAudOut is the audio output zones
+type OmegaAudOut struct {
+	ZoneOut1 *OmegaZoneOut `json:"zoneOut1,omitempty"`
+	ZoneOut2 *OmegaZoneOut `json:"zoneOut2,omitempty"`
+}
+
+// OmegaZoneOut is a single audio zone
+type OmegaZoneOut struct {
+	AnalogOut *OmegaAnalogOut `json:"analogOut,omitempty"`
+	AudioVol  *int            `json:"audioVol,omitempty"`
+	MicMixVol *int            `json:"micMixVol,omitempty"`
+	AudioSrc  *string         `json:"audioSrc,omitempty"`
+}
+
+// OmegaAnalogOut is the analog output of an audio zone
+type OmegaAnalogOut struct {
+	AudioMute  *bool `json:"audioMute,omitempty"`
+	AudioDelay *int  `json:"audioDelay,omitempty"`
+}
+
+// OmegaNetwork is the network section of the config
+type OmegaNetwork struct {
+	Eth0 *OmegaEth `json:"eth0,omitempty"`
+}
+
+// OmegaEth is a network interface
+type OmegaEth struct {
+	MacAddr    *string          `json:"macAddr,omitempty"`
+	DomainName *string          `json:"domainName,omitempty"`
+	DNSServer1 *string          `json:"dnsServer1,omitempty"`
+	DNSServer2 *string          `json:"dnsServer2,omitempty"`
+	IPSettings *OmegaIPSettings `json:"ipSettings,omitempty"`
+	LastIpaddr *string          `json:"lastIpaddr,omitempty"`
+	BootProto  *string          `json:"bootProto,omitempty"`
+}
+
+// OmegaIPSettings is the static ip settings of a network interface
+type OmegaIPSettings struct {
+	TelnetPort *int    `json:"telnetPort,omitempty"`
+	Ipaddr     *string `json:"ipaddr,omitempty"`
+	Netmask    *string `json:"netmask,omitempty"`
+	Gateway    *string `json:"gateway,omitempty"`
+}
+
+// OmegaSystem is the system section of the config
+type OmegaSystem struct {
+	PowerStatus     *string               `json:"powerStatus,omitempty"`
+	VendorID        *string               `json:"vendorID,omitempty"`
+	Model           *string               `json:"model,omitempty"`
+	SerialNumber    *string               `json:"serialNumber,omitempty"`
+	FirmwareVersion *OmegaFirmwareVersion `json:"firmwareVersion,omitempty"`
+}
+
+// OmegaFirmwareVersion is the firmware version of each component in the device
+type OmegaFirmwareVersion struct {
+	Package          *string `json:"package,omitempty"`
+	MasterMCU        *string `json:"masterMCU,omitempty"`
+	SlaveMCU         *string `json:"slaveMCU,omitempty"`
+	Fpga             *string `json:"fpga,omitempty"`
+	ScalerChip       *string `json:"scalerChip,omitempty"`
+	Audio            *string `json:"audio,omitempty"`
+	Usb              *string `json:"usb,omitempty"`
+	ValensA          *string `json:"valens_A,omitempty"`
+	ValensB          *string `json:"valens_B,omitempty"`
+	ValensC          *string `json:"valens_C,omitempty"`
+	TransceiverChipA *string `json:"transceiverChip_A,omitempty"`
+	TransceiverChipB *string `json:"transceiverChip_B,omitempty"`
+	TransceiverChipC *string `json:"transceiverChip_C,omitempty"`
+	TransceiverChipE *string `json:"transceiverChip_E,omitempty"`
+	TransceiverChipF *string `json:"transceiverChip_F,omitempty"`
+}
+
+// Merge copies every field that is set in other into c. Sections that are set in both are merged recursively.
+func (c *OmegaConfig) Merge(other *OmegaConfig) {
+	if other == nil {
+		return
+	}
+
+	mergeOmegaValue(reflect.ValueOf(c).Elem(), reflect.ValueOf(other).Elem())
+}
+
+func mergeOmegaValue(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		sf, df := src.Field(i), dst.Field(i)
+		if sf.Kind() != reflect.Ptr || sf.IsNil() {
+			continue
+		}
+
+		if sf.Elem().Kind() == reflect.Struct {
+			if df.IsNil() {
+				df.Set(reflect.New(sf.Elem().Type()))
+			}
+
+			mergeOmegaValue(df.Elem(), sf.Elem())
+			continue
+		}
+
+		val := reflect.New(sf.Elem().Type())
+		val.Elem().Set(sf.Elem())
+		df.Set(val)
+	}
+}
+
+// omegaResponse is the body config.cgi sends back. if the device rejects a request, it fills in Error
+type omegaResponse struct {
+	OmegaConfig
+
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+// deviceError returns the error reported by the device, if there was one
+func (r omegaResponse) deviceError() string {
+	raw := strings.TrimSpace(string(r.Error))
+	switch raw {
+	case "", "null", "false", `""`, "{}":
+		return ""
+	}
+
+	var msg string
+	if err := json.Unmarshal(r.Error, &msg); err == nil {
+		return msg
+	}
+
+	return raw
+}
+
+func (vs *AtlonaVideoSwitcher6x2) configURL() string {
+	return fmt.Sprintf("http://%s/cgi-bin/config.cgi", vs.Address)
+}
+
+func (vs *AtlonaVideoSwitcher6x2) sendConfig(ctx context.Context, method string, cfg *OmegaConfig) (*OmegaConfig, error) {
+	reqBody, err := json.Marshal(map[string]*OmegaConfig{method: cfg})
+	if err != nil {
+		return nil, fmt.Errorf("unable to build %s request: %w", method, err)
+	}
+
+	body, err := vs.make6x2request(ctx, vs.configURL(), string(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp omegaResponse
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal %s response: %w", method, err)
+		}
+	}
+
+	if msg := resp.deviceError(); msg != "" {
+		return nil, &DeviceError{Address: vs.Address, Message: msg}
+	}
+
+	return &resp.OmegaConfig, nil
+}
+
+// getConfig asks the device for every section set in the queries, in a single request, and returns the merged response
+func (vs *AtlonaVideoSwitcher6x2) getConfig(ctx context.Context, queries ...*OmegaConfig) (*OmegaConfig, error) {
+	query := &OmegaConfig{}
+	for _, q := range queries {
+		query.Merge(q)
+	}
+
+	cfg, err := vs.sendConfig(ctx, "getConfig", query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// setConfig writes every field set in cfg to the device
+func (vs *AtlonaVideoSwitcher6x2) setConfig(ctx context.Context, cfg *OmegaConfig) error {
+	if _, err := vs.sendConfig(ctx, "setConfig", cfg); err != nil {
+		return fmt.Errorf("unable to set config: %w", err)
+	}
+
+	return nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
+
+func derefBool(b *bool) bool {
+	return b != nil && *b
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,272 @@
+package atlona
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// omegaDevice is a fake config.cgi. It records the body of every request, and answers them with replies in order
+type omegaDevice struct {
+	replies  []string
+	requests []string
+}
+
+func (d *omegaDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != "POST" || r.URL.Path != "/cgi-bin/config.cgi" {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, _ := ioutil.ReadAll(r.Body)
+	d.requests = append(d.requests, string(b))
+
+	if len(d.replies) == 0 {
+		w.Write([]byte("{}"))
+		return
+	}
+
+	w.Write([]byte(d.replies[0]))
+	d.replies = d.replies[1:]
+}
+
+// newOmegaSwitcher starts d, and returns a switcher that talks to it and a func that shuts it down
+func newOmegaSwitcher(d *omegaDevice) (*AtlonaVideoSwitcher6x2, func()) {
+	server := httptest.NewServer(d)
+
+	vs := &AtlonaVideoSwitcher6x2{
+		Address:  strings.TrimPrefix(server.URL, "http://"),
+		Username: "admin",
+		Password: "secret",
+	}
+
+	return vs, server.Close
+}
+
+// checkOmegaRequests checks that the json bodies sent to d match expected
+func checkOmegaRequests(t *testing.T, d *omegaDevice, expected []string) {
+	t.Helper()
+
+	if len(d.requests) != len(expected) {
+		t.Fatalf("got %v requests %v, expected %v", len(d.requests), d.requests, len(expected))
+	}
+
+	for i := range expected {
+		var got, exp interface{}
+		if err := json.Unmarshal([]byte(d.requests[i]), &got); err != nil {
+			t.Fatalf("request %v is not json: %s", i, d.requests[i])
+		}
+
+		if err := json.Unmarshal([]byte(expected[i]), &exp); err != nil {
+			t.Fatalf("expected request %v is not json: %s", i, expected[i])
+		}
+
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("got request %s, expected %s", d.requests[i], expected[i])
+		}
+	}
+}
+
+func TestOmegaConfigMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *OmegaConfig
+		other    *OmegaConfig
+		expected *OmegaConfig
+	}{
+		{
+			name:     "nil",
+			cfg:      &OmegaConfig{System: &OmegaSystem{}},
+			expected: &OmegaConfig{System: &OmegaSystem{}},
+		},
+		{
+			name:     "separate sections",
+			cfg:      &OmegaConfig{System: &OmegaSystem{}},
+			other:    &OmegaConfig{Network: &OmegaNetwork{Eth0: &OmegaEth{}}},
+			expected: &OmegaConfig{System: &OmegaSystem{}, Network: &OmegaNetwork{Eth0: &OmegaEth{}}},
+		},
+		{
+			name:  "same section",
+			cfg:   &OmegaConfig{Audio: &OmegaAudio{AudIn: &OmegaAudIn{Mic: &OmegaMic{Gain: intPtr(20)}}}},
+			other: &OmegaConfig{Audio: &OmegaAudio{AudIn: &OmegaAudIn{Mic: &OmegaMic{AudioMute: boolPtr(true)}}, AudOut: &OmegaAudOut{}}},
+			expected: &OmegaConfig{Audio: &OmegaAudio{
+				AudIn:  &OmegaAudIn{Mic: &OmegaMic{Gain: intPtr(20), AudioMute: boolPtr(true)}},
+				AudOut: &OmegaAudOut{},
+			}},
+		},
+		{
+			name:     "values are overwritten",
+			cfg:      &OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{ZoneOut1: &OmegaZoneOut{AudioVol: intPtr(-20), AudioSrc: stringPtr("hdmi1")}}}},
+			other:    &OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{ZoneOut1: &OmegaZoneOut{AudioVol: intPtr(-10)}}}},
+			expected: &OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{ZoneOut1: &OmegaZoneOut{AudioVol: intPtr(-10), AudioSrc: stringPtr("hdmi1")}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Merge(tt.other)
+
+			// merged values are copies, so changing other afterwards doesn't change cfg
+			if tt.other != nil && tt.other.Audio != nil && tt.other.Audio.AudOut != nil && tt.other.Audio.AudOut.ZoneOut1 != nil {
+				*tt.other.Audio.AudOut.ZoneOut1.AudioVol = 0
+			}
+
+			if !reflect.DeepEqual(tt.cfg, tt.expected) {
+				got, _ := json.Marshal(tt.cfg)
+				exp, _ := json.Marshal(tt.expected)
+				t.Errorf("got %s, expected %s", got, exp)
+			}
+		})
+	}
+}
+
+func TestOmegaDeviceError(t *testing.T) {
+	tests := []struct {
+		body string
+		msg  string
+	}{
+		{body: `{}`},
+		{body: `{"error":null}`},
+		{body: `{"error":false}`},
+		{body: `{"error":""}`},
+		{body: `{"error":{}}`},
+		{body: `{"error":"invalid value for audioVol"}`, msg: "invalid value for audioVol"},
+		{body: `{"error":{"code":3,"message":"read only"}}`, msg: `{"code":3,"message":"read only"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var resp omegaResponse
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("unable to unmarshal: %s", err)
+			}
+
+			if msg := resp.deviceError(); msg != tt.msg {
+				t.Errorf("got %q, expected %q", msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestOmegaSendConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		call     func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (*OmegaConfig, error)
+		request  string
+		expected *OmegaConfig
+		err      error
+	}{
+		{
+			name:  "get merges queries",
+			reply: `{"system":{"model":"AT-OME-PS62"},"network":{"eth0":{"macAddr":"b8:98:b0:0a:0b:0c"}}}`,
+			call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (*OmegaConfig, error) {
+				return vs.getConfig(ctx,
+					&OmegaConfig{System: &OmegaSystem{}},
+					&OmegaConfig{Network: &OmegaNetwork{Eth0: &OmegaEth{}}},
+				)
+			},
+			request: `{"getConfig":{"system":{},"network":{"eth0":{}}}}`,
+			expected: &OmegaConfig{
+				System:  &OmegaSystem{Model: stringPtr("AT-OME-PS62")},
+				Network: &OmegaNetwork{Eth0: &OmegaEth{MacAddr: stringPtr("b8:98:b0:0a:0b:0c")}},
+			},
+		},
+		{
+			name: "set",
+			call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (*OmegaConfig, error) {
+				return nil, vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{ZoneOut2: &OmegaZoneOut{AudioVol: intPtr(-30)}}}})
+			},
+			request: `{"setConfig":{"audio":{"audOut":{"zoneOut2":{"audioVol":-30}}}}}`,
+		},
+		{
+			name:  "device error",
+			reply: `{"error":"audioVol out of range"}`,
+			call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (*OmegaConfig, error) {
+				return nil, vs.setConfig(ctx, &OmegaConfig{Audio: &OmegaAudio{AudOut: &OmegaAudOut{ZoneOut1: &OmegaZoneOut{AudioVol: intPtr(50)}}}})
+			},
+			request: `{"setConfig":{"audio":{"audOut":{"zoneOut1":{"audioVol":50}}}}}`,
+			err:     &DeviceError{},
+		},
+		{
+			name:  "invalid response",
+			reply: `<html>busy</html>`,
+			call: func(ctx context.Context, vs *AtlonaVideoSwitcher6x2) (*OmegaConfig, error) {
+				return vs.getConfig(ctx, &OmegaConfig{System: &OmegaSystem{}})
+			},
+			request: `{"getConfig":{"system":{}}}`,
+			err:     errors.New("invalid response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &omegaDevice{}
+			if tt.reply != "" {
+				d.replies = []string{tt.reply}
+			}
+
+			vs, closeFunc := newOmegaSwitcher(d)
+			defer closeFunc()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			cfg, err := tt.call(ctx, vs)
+			checkOmegaRequests(t, d, []string{tt.request})
+
+			var devErr *DeviceError
+			switch {
+			case tt.err == nil && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case tt.err != nil && err == nil:
+				t.Fatalf("expected an error")
+			case errors.As(tt.err, &devErr) && !errors.As(err, &devErr):
+				t.Fatalf("got error %v, expected a *DeviceError", err)
+			}
+
+			if tt.expected != nil && !reflect.DeepEqual(cfg, tt.expected) {
+				got, _ := json.Marshal(cfg)
+				exp, _ := json.Marshal(tt.expected)
+				t.Errorf("got %s, expected %s", got, exp)
+			}
+		})
+	}
+}
+
+func TestOmegaAuth(t *testing.T) {
+	d := &omegaDevice{}
+	vs, closeFunc := newOmegaSwitcher(d)
+	defer closeFunc()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var authErr *AuthError
+
+	vs.Password = "wrong"
+	if _, err := vs.GetOmegaInfo(ctx); !errors.As(err, &authErr) {
+		t.Errorf("got error %v with the wrong password, expected an *AuthError", err)
+	}
+
+	vs.Username = ""
+	if _, err := vs.GetOmegaInfo(ctx); !errors.As(err, &authErr) {
+		t.Errorf("got error %v without a username, expected an *AuthError", err)
+	}
+
+	if len(d.requests) != 0 {
+		t.Errorf("got requests %v, expected none to be accepted", d.requests)
+	}
+}